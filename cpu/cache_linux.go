@@ -0,0 +1,87 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// getCaches walks /sys/devices/system/cpu/cpuN/cache/indexM for every logical
+// processor and returns each distinct cache instance.  A cache shared between
+// several processors (such as an L3 shared by a whole package) appears only
+// once, with all of the sharing processors listed.
+func getCaches() ([]CacheInfo, error) {
+	procIDs, err := sysCpuIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	caches := []CacheInfo{}
+	seen := map[string]struct{}{}
+	for _, procID := range procIDs {
+		// iterate over each cache this CPU can use
+		for i := 0; ; i++ {
+			dir := fmt.Sprintf("cpu%d/cache/index%d/", procID, i)
+
+			sharedList, ok := sysCpuList(dir + "shared_cpu_list")
+			if !ok {
+				break
+			}
+
+			cache := CacheInfo{SharedCPUs: make([]uint64, 0, len(sharedList))}
+			for sharedProcID := range sharedList {
+				cache.SharedCPUs = append(cache.SharedCPUs, sharedProcID)
+			}
+			sort.Slice(cache.SharedCPUs, func(i, j int) bool { return cache.SharedCPUs[i] < cache.SharedCPUs[j] })
+
+			if level, ok := sysCpuInt(dir + "level"); ok {
+				cache.Level = level
+			}
+			if cacheType, ok := sysCpuString(dir + "type"); ok {
+				cache.Type = cacheType
+			}
+
+			// the same instance is visible from each CPU sharing it, so only
+			// count it once
+			key := fmt.Sprintf("%d/%s/%v", cache.Level, cache.Type, cache.SharedCPUs)
+			if _, found := seen[key]; found {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if size, ok := sysCpuSize(dir + "size"); ok {
+				cache.SizeBytes = size
+			}
+			if ways, ok := sysCpuInt(dir + "ways_of_associativity"); ok {
+				cache.Associativity = ways
+			}
+			if lineSize, ok := sysCpuInt(dir + "coherency_line_size"); ok {
+				cache.LineSizeBytes = lineSize
+			}
+
+			caches = append(caches, cache)
+		}
+	}
+
+	sort.SliceStable(caches, func(i, j int) bool {
+		if caches[i].Level != caches[j].Level {
+			return caches[i].Level < caches[j].Level
+		}
+		return caches[i].Type < caches[j].Type
+	})
+
+	return caches, nil
+}
+
+// cacheSizesByLevel sums the size of the given caches for each level
+func cacheSizesByLevel(caches []CacheInfo) map[uint64]uint64 {
+	sizes := map[uint64]uint64{}
+	for _, cache := range caches {
+		sizes[cache.Level] += cache.SizeBytes
+	}
+	return sizes
+}
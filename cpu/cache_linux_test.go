@@ -0,0 +1,66 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// cacheFiles returns the sysfs files describing one cache index of a CPU
+func cacheFiles(cpu, index, level, cacheType, size, ways, shared string) map[string]string {
+	dir := "sys/devices/system/cpu/cpu" + cpu + "/cache/index" + index + "/"
+	return map[string]string{
+		dir + "level":                 level + "\n",
+		dir + "type":                  cacheType + "\n",
+		dir + "size":                  size + "\n",
+		dir + "ways_of_associativity": ways + "\n",
+		dir + "coherency_line_size":   "64\n",
+		dir + "shared_cpu_list":       shared + "\n",
+	}
+}
+
+func TestGetCaches(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	// two cores, each with private L1d/L1i/L2 and a shared L3
+	for _, cpu := range []string{"0", "1"} {
+		utils.WriteTestFiles(t, prefix, cacheFiles(cpu, "0", "1", "Data", "48K", "12", cpu))
+		utils.WriteTestFiles(t, prefix, cacheFiles(cpu, "1", "1", "Instruction", "32K", "8", cpu))
+		utils.WriteTestFiles(t, prefix, cacheFiles(cpu, "2", "2", "Unified", "2048K", "16", cpu))
+		utils.WriteTestFiles(t, prefix, cacheFiles(cpu, "3", "3", "Unified", "30M", "20", "0-1"))
+	}
+	// not a CPU
+	utils.WriteTestFiles(t, prefix, map[string]string{"sys/devices/system/cpu/cpufreq/boost": "1\n"})
+
+	caches, err := getCaches()
+	require.NoError(t, err)
+	require.Equal(t, []CacheInfo{
+		{Level: 1, Type: "Data", SizeBytes: 48 * 1024, Associativity: 12, LineSizeBytes: 64, SharedCPUs: []uint64{0}},
+		{Level: 1, Type: "Data", SizeBytes: 48 * 1024, Associativity: 12, LineSizeBytes: 64, SharedCPUs: []uint64{1}},
+		{Level: 1, Type: "Instruction", SizeBytes: 32 * 1024, Associativity: 8, LineSizeBytes: 64, SharedCPUs: []uint64{0}},
+		{Level: 1, Type: "Instruction", SizeBytes: 32 * 1024, Associativity: 8, LineSizeBytes: 64, SharedCPUs: []uint64{1}},
+		{Level: 2, Type: "Unified", SizeBytes: 2048 * 1024, Associativity: 16, LineSizeBytes: 64, SharedCPUs: []uint64{0}},
+		{Level: 2, Type: "Unified", SizeBytes: 2048 * 1024, Associativity: 16, LineSizeBytes: 64, SharedCPUs: []uint64{1}},
+		{Level: 3, Type: "Unified", SizeBytes: 30 * 1024 * 1024, Associativity: 20, LineSizeBytes: 64, SharedCPUs: []uint64{0, 1}},
+	}, caches)
+
+	require.Equal(t, map[uint64]uint64{
+		1: 2 * (48 + 32) * 1024,
+		2: 2 * 2048 * 1024,
+		3: 30 * 1024 * 1024,
+	}, cacheSizesByLevel(caches))
+}
+
+func TestGetCachesMissing(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	_, err := getCaches()
+	require.Error(t, err)
+}
@@ -7,9 +7,13 @@
 package cpu
 
 import (
+	"flag"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/gohai/utils"
 )
@@ -39,18 +43,64 @@ type Cpu struct {
 
 	// CpuPkgs the CPU pkg count (Windows only)
 	CpuPkgs uint64
-	// CpuNumaNodes the CPU numa node count (Windows only)
+	// CpuNumaNodes the CPU numa node count (Windows and Linux only)
 	CpuNumaNodes uint64
-	// CacheSizeL1Bytes the CPU L1 cache size (Windows only)
+	// CacheSizeL1Bytes the CPU L1 cache size (Windows and Linux only)
 	CacheSizeL1Bytes uint64
-	// CacheSizeL2Bytes the CPU L2 cache size (Windows only)
+	// CacheSizeL2Bytes the CPU L2 cache size (Windows and Linux only)
 	CacheSizeL2Bytes uint64
-	// CacheSizeL3 the CPU L3 cache size (Windows only)
+	// CacheSizeL3 the CPU L3 cache size (Windows and Linux only)
 	CacheSizeL3Bytes uint64
+
+	// Caches lists each cache instance of the CPU (Linux only)
+	Caches []CacheInfo
+	// Virtualization tells whether the host is bare metal, a virtual machine
+	// or a container (Linux only)
+	Virtualization *Virtualization
+	// EffectiveCPUs describes the CPUs available to the current process once
+	// its cgroup limits are applied (Linux only)
+	EffectiveCPUs *EffectiveCPUs
+	// NumaNodes describes each NUMA node of the host (Linux only)
+	NumaNodes []NumaNode
+	// CPUID is the identification decoded from the CPUID instruction, along
+	// with its disagreements with /proc/cpuinfo (Linux amd64 only)
+	CPUID *CPUIDInfo
+	// Sockets lists the microcode and identification of each socket (Linux
+	// only)
+	Sockets []SocketInfo
+}
+
+// CacheInfo describes a single cache instance, which may be shared between
+// several logical processors
+type CacheInfo struct {
+	// Level is the cache level (1, 2, 3, ...)
+	Level uint64 `json:"level"`
+	// Type is the type of the cache: "Data", "Instruction" or "Unified"
+	Type string `json:"type"`
+	// SizeBytes is the size of the cache in bytes
+	SizeBytes uint64 `json:"size_bytes"`
+	// Associativity is the number of ways of associativity of the cache
+	Associativity uint64 `json:"associativity"`
+	// LineSizeBytes is the coherency line size of the cache in bytes
+	LineSizeBytes uint64 `json:"line_size_bytes"`
+	// SharedCPUs lists the logical processors sharing this cache instance
+	SharedCPUs []uint64 `json:"shared_cpus"`
 }
 
 const name = "cpu"
 
+var options struct {
+	sampleWindow time.Duration
+	preferCPUID  bool
+	power        bool
+}
+
+func init() {
+	flag.DurationVar(&options.sampleWindow, name+"-sample-window", 0, "Sample the CPU utilization over this duration, eg. '1s' (Linux only, disabled when 0)")
+	flag.BoolVar(&options.preferCPUID, name+"-prefer-cpuid", false, "Identify the CPU from CPUID rather than /proc/cpuinfo (Linux amd64 only)")
+	flag.BoolVar(&options.power, name+"-power", false, "Collect the power limits, thermal zones and idle states of the CPU (Linux only)")
+}
+
 // Name returns the name of the package
 func (cpu *Cpu) Name() string {
 	return name
@@ -64,6 +114,62 @@ func (cpu *Cpu) Collect() (result interface{}, err error) {
 	return
 }
 
+// Details collects the structured CPU details which do not fit in the flat
+// legacy payload of Cpu, under their own "cpu_details" key
+type Details struct{}
+
+// Name returns the name of the collector
+func (details *Details) Name() string {
+	return name + "_details"
+}
+
+// Collect collects the structured CPU details.
+// Returns an object which can be converted to a JSON or an error if nothing could be collected.
+// Tries to collect as much information as possible.
+func (details *Details) Collect() (result interface{}, err error) {
+	// any warnings are dropped as the payload has no room for them
+	c := &Cpu{}
+	getCPUDetails(c, &[]string{})
+
+	info := make(map[string]interface{})
+	if len(c.Caches) != 0 {
+		info["caches"] = c.Caches
+	}
+	if c.Virtualization != nil {
+		info["virtualization"] = c.Virtualization
+	}
+	if c.EffectiveCPUs != nil {
+		info["effective_cpus"] = c.EffectiveCPUs
+	}
+	if len(c.NumaNodes) != 0 {
+		info["numa"] = c.NumaNodes
+	}
+	if c.CPUID != nil {
+		info["cpuid"] = c.CPUID
+	}
+	if len(c.Sockets) != 0 {
+		info["sockets"] = c.Sockets
+	}
+
+	if options.sampleWindow > 0 {
+		if utilization, err := SampleUtilization(options.sampleWindow); err == nil {
+			info["utilization"] = utilization
+		} else {
+			log.Warnf("[%s] could not sample CPU utilization: %s", details.Name(), err)
+		}
+	}
+
+	if options.power {
+		if power, err := GetPower(); err == nil {
+			info["power"] = power
+		} else {
+			log.Warnf("[%s] could not collect CPU power details: %s", details.Name(), err)
+		}
+	}
+
+	return info, nil
+}
+
 // Get returns a CPU struct already initialized, a list of warnings and an error. The method will try to collect as much
 // metadata as possible, an error is returned if nothing could be collected. The list of warnings contains errors if
 // some metadata could not be collected.
@@ -104,5 +210,7 @@ func Get() (*Cpu, []string, error) {
 		warnings = append(warnings, fmt.Sprintf("could not collect cache size: %s", err))
 	}
 
+	getCPUDetails(c, &warnings)
+
 	return c, warnings, nil
 }
@@ -0,0 +1,14 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build linux && (arm || arm64)
+// +build linux
+// +build arm arm64
+
+package cpu
+
+func getCPUInfo() (map[string]string, error) {
+	return getARMCPUInfo()
+}
@@ -3,8 +3,8 @@
 // Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
 // Copyright 2014-present Datadog, Inc.
 
-//go:build linux && !arm64
-// +build linux,!arm64
+//go:build linux && !arm && !arm64 && !ppc64 && !ppc64le && !riscv64 && !s390x
+// +build linux,!arm,!arm64,!ppc64,!ppc64le,!riscv64,!s390x
 
 package cpu
 
@@ -36,7 +36,20 @@ var perPhysicalProcValues = []string{
 func getCPUInfo() (cpuInfo map[string]string, err error) {
 	lines, err := readProcFile()
 	if err != nil {
-		return
+		// /proc/cpuinfo may be masked in minimal containers and sandboxes, in
+		// which case the CPU is identified from CPUID and sysfs instead
+		cpuInfo = make(map[string]string)
+		if !addCPUIDCpuInfo(cpuInfo, true) {
+			return nil, err
+		}
+		if procIDs, err := sysCpuIDs(); err == nil {
+			// matches the "cpu cores" of each physical id summed up below
+			cpuInfo["cpu_cores"] = strconv.Itoa(sysCpuPhysicalCores(procIDs))
+			cpuInfo["cpu_logical_processors"] = strconv.Itoa(len(procIDs))
+		}
+		addSysCacheSizes(cpuInfo)
+		addSysNumaNodes(cpuInfo)
+		return cpuInfo, nil
 	}
 
 	cpuInfo = make(map[string]string)
@@ -68,11 +81,20 @@ func getCPUInfo() (cpuInfo map[string]string, err error) {
 		}
 	}
 
+	// 'cache size' only reports a single cache (typically the L3 of a
+	// package), so the per-level sizes are drawn from /sys/devices/system/cpu
+	addSysCacheSizes(cpuInfo)
+	addSysNumaNodes(cpuInfo)
+
+	// fill in the fields missing from /proc/cpuinfo, or replace them all
+	// when CPUID is preferred
+	addCPUIDCpuInfo(cpuInfo, options.preferCPUID)
+
 	return
 }
 
 func readProcFile() (lines []string, err error) {
-	file, err := os.Open("/proc/cpuinfo")
+	file, err := os.Open(prefix + "/proc/cpuinfo")
 
 	if err != nil {
 		return
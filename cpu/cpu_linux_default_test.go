@@ -0,0 +1,38 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build linux && !arm && !arm64 && !ppc64 && !ppc64le && !riscv64 && !s390x
+// +build linux,!arm,!arm64,!ppc64,!ppc64le,!riscv64,!s390x
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCPUInfoMaskedCpuInfo(t *testing.T) {
+	withProcCpuInfo(t, "")
+	withCPUID(t, intelLeaves())
+	// two packages of two cores of two threads, core IDs restarting at 0 on
+	// each package
+	withTopology(t, [][2]int{{0, 0}, {0, 0}, {0, 1}, {0, 1}, {1, 0}, {1, 0}, {1, 1}, {1, 1}})
+
+	cpuInfo, err := getCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, "GenuineIntel", cpuInfo["vendor_id"])
+	require.Equal(t, "143", cpuInfo["model"])
+	require.Equal(t, "4", cpuInfo["cpu_cores"])
+	require.Equal(t, "8", cpuInfo["cpu_logical_processors"])
+}
+
+func TestGetCPUInfoMaskedCpuInfoNoCPUID(t *testing.T) {
+	withProcCpuInfo(t, "")
+	withCPUID(t, nil)
+
+	_, err := getCPUInfo()
+	require.Error(t, err)
+}
@@ -0,0 +1,380 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CPUIDInfo is the identification of an x86 CPU decoded from the CPUID
+// instruction, independently of /proc/cpuinfo
+type CPUIDInfo struct {
+	// Vendor is the vendor signature (eg. "GenuineIntel", "AuthenticAMD")
+	Vendor string `json:"vendor"`
+	// Brand is the processor brand string
+	Brand string `json:"brand"`
+	// Family is the display family, including the extended family
+	Family uint32 `json:"family"`
+	// Model is the display model, including the extended model
+	Model uint32 `json:"model"`
+	// Stepping is the stepping ID
+	Stepping uint32 `json:"stepping"`
+	// Features lists the supported features, named as in the flags of
+	// /proc/cpuinfo
+	Features []string `json:"features"`
+	// Caches lists the cache descriptors of leaf 4 (or 0x8000001D on AMD)
+	Caches []CPUIDCache `json:"caches"`
+	// Topology lists the levels of leaf 0x1F (or 0xB), starting with SMT
+	Topology []CPUIDTopologyLevel `json:"topology"`
+	// Mismatches lists the fields on which CPUID and /proc/cpuinfo disagree
+	Mismatches []CPUIDMismatch `json:"mismatches"`
+}
+
+// CPUIDCache is a cache descriptor reported by CPUID
+type CPUIDCache struct {
+	// Level is the cache level (1, 2, 3, ...)
+	Level uint32 `json:"level"`
+	// Type is the type of the cache: "Data", "Instruction" or "Unified"
+	Type string `json:"type"`
+	// SizeBytes is the size of the cache in bytes
+	SizeBytes uint64 `json:"size_bytes"`
+	// Associativity is the number of ways of associativity of the cache
+	Associativity uint32 `json:"associativity"`
+	// LineSizeBytes is the line size of the cache in bytes
+	LineSizeBytes uint32 `json:"line_size_bytes"`
+	// SharedThreads is the maximum number of logical processors sharing the
+	// cache
+	SharedThreads uint32 `json:"shared_threads"`
+}
+
+// CPUIDTopologyLevel is a level of the extended topology enumeration
+type CPUIDTopologyLevel struct {
+	// Type is the level type: "SMT", "Core", "Module", "Tile" or "Die"
+	Type string `json:"type"`
+	// ShiftBits is the number of bits of the x2APIC ID to shift right to get
+	// the ID of the next level
+	ShiftBits uint32 `json:"shift_bits"`
+	// LogicalProcessors is the number of logical processors at this level
+	LogicalProcessors uint32 `json:"logical_processors"`
+}
+
+// CPUIDMismatch is a field on which CPUID and /proc/cpuinfo disagree, which
+// happens when /proc/cpuinfo is masked or virtualized (eg. by LXCFS)
+type CPUIDMismatch struct {
+	// Field is the /proc/cpuinfo field name (eg. "vendor_id")
+	Field string `json:"field"`
+	// CPUID is the value decoded from CPUID
+	CPUID string `json:"cpuid"`
+	// CpuInfo is the value read from /proc/cpuinfo
+	CpuInfo string `json:"cpuinfo"`
+}
+
+// cpuidFeature is a feature bit: bit of register reg ("ebx", "ecx" or
+// "edx") of the given leaf (subleaf 0)
+type cpuidFeature struct {
+	leaf uint32
+	reg  string
+	bit  uint
+	name string
+}
+
+// cpuidFeatures lists the decoded feature bits, see the Intel SDM volume 2A
+// and the AMD APM volume 3
+var cpuidFeatures = []cpuidFeature{
+	{1, "edx", 0, "fpu"},
+	{1, "edx", 1, "vme"},
+	{1, "edx", 2, "de"},
+	{1, "edx", 3, "pse"},
+	{1, "edx", 4, "tsc"},
+	{1, "edx", 5, "msr"},
+	{1, "edx", 6, "pae"},
+	{1, "edx", 7, "mce"},
+	{1, "edx", 8, "cx8"},
+	{1, "edx", 9, "apic"},
+	{1, "edx", 11, "sep"},
+	{1, "edx", 12, "mtrr"},
+	{1, "edx", 13, "pge"},
+	{1, "edx", 14, "mca"},
+	{1, "edx", 15, "cmov"},
+	{1, "edx", 16, "pat"},
+	{1, "edx", 17, "pse36"},
+	{1, "edx", 19, "clflush"},
+	{1, "edx", 23, "mmx"},
+	{1, "edx", 24, "fxsr"},
+	{1, "edx", 25, "sse"},
+	{1, "edx", 26, "sse2"},
+	{1, "edx", 27, "ss"},
+	{1, "edx", 28, "ht"},
+	{1, "ecx", 0, "pni"},
+	{1, "ecx", 1, "pclmulqdq"},
+	{1, "ecx", 3, "monitor"},
+	{1, "ecx", 5, "vmx"},
+	{1, "ecx", 9, "ssse3"},
+	{1, "ecx", 12, "fma"},
+	{1, "ecx", 13, "cx16"},
+	{1, "ecx", 17, "pcid"},
+	{1, "ecx", 19, "sse4_1"},
+	{1, "ecx", 20, "sse4_2"},
+	{1, "ecx", 21, "x2apic"},
+	{1, "ecx", 22, "movbe"},
+	{1, "ecx", 23, "popcnt"},
+	{1, "ecx", 25, "aes"},
+	{1, "ecx", 26, "xsave"},
+	{1, "ecx", 28, "avx"},
+	{1, "ecx", 29, "f16c"},
+	{1, "ecx", 30, "rdrand"},
+	{1, "ecx", 31, "hypervisor"},
+	{7, "ebx", 0, "fsgsbase"},
+	{7, "ebx", 3, "bmi1"},
+	{7, "ebx", 4, "hle"},
+	{7, "ebx", 5, "avx2"},
+	{7, "ebx", 7, "smep"},
+	{7, "ebx", 8, "bmi2"},
+	{7, "ebx", 9, "erms"},
+	{7, "ebx", 10, "invpcid"},
+	{7, "ebx", 11, "rtm"},
+	{7, "ebx", 16, "avx512f"},
+	{7, "ebx", 17, "avx512dq"},
+	{7, "ebx", 18, "rdseed"},
+	{7, "ebx", 19, "adx"},
+	{7, "ebx", 20, "smap"},
+	{7, "ebx", 21, "avx512ifma"},
+	{7, "ebx", 23, "clflushopt"},
+	{7, "ebx", 24, "clwb"},
+	{7, "ebx", 28, "avx512cd"},
+	{7, "ebx", 29, "sha_ni"},
+	{7, "ebx", 30, "avx512bw"},
+	{7, "ebx", 31, "avx512vl"},
+	{7, "ecx", 1, "avx512vbmi"},
+	{7, "ecx", 2, "umip"},
+	{7, "ecx", 3, "pku"},
+	{7, "ecx", 6, "avx512_vbmi2"},
+	{7, "ecx", 8, "gfni"},
+	{7, "ecx", 9, "vaes"},
+	{7, "ecx", 10, "vpclmulqdq"},
+	{7, "ecx", 11, "avx512_vnni"},
+	{7, "ecx", 12, "avx512_bitalg"},
+	{7, "ecx", 14, "avx512_vpopcntdq"},
+	{7, "ecx", 22, "rdpid"},
+	{7, "edx", 4, "fsrm"},
+	{7, "edx", 10, "md_clear"},
+	{7, "edx", 14, "serialize"},
+	{7, "edx", 22, "amx_bf16"},
+	{7, "edx", 23, "avx512_fp16"},
+	{7, "edx", 24, "amx_tile"},
+	{7, "edx", 25, "amx_int8"},
+	{0x80000001, "ecx", 0, "lahf_lm"},
+	{0x80000001, "ecx", 2, "svm"},
+	{0x80000001, "ecx", 5, "abm"},
+	{0x80000001, "ecx", 6, "sse4a"},
+	{0x80000001, "ecx", 8, "3dnowprefetch"},
+	{0x80000001, "ecx", 22, "topoext"},
+	{0x80000001, "edx", 11, "syscall"},
+	{0x80000001, "edx", 20, "nx"},
+	{0x80000001, "edx", 26, "pdpe1gb"},
+	{0x80000001, "edx", 27, "rdtscp"},
+	{0x80000001, "edx", 29, "lm"},
+}
+
+// cpuidCacheTypes maps the cache type field of leaves 4 and 0x8000001D to a
+// name; 0 means there are no more caches
+var cpuidCacheTypes = map[uint32]string{
+	1: "Data",
+	2: "Instruction",
+	3: "Unified",
+}
+
+// cpuidTopologyTypes maps the level type field of leaves 0xB and 0x1F to a
+// name; 0 means there are no more levels
+var cpuidTopologyTypes = map[uint32]string{
+	1: "SMT",
+	2: "Core",
+	3: "Module",
+	4: "Tile",
+	5: "Die",
+}
+
+// getCPUIDInfo decodes the identification, features, caches and topology of
+// the CPU from CPUID.  Being executed on whichever processor the current
+// thread runs on, it assumes all processors are identical.
+func getCPUIDInfo() (*CPUIDInfo, error) {
+	if cpuidFunc == nil {
+		return nil, errors.New("CPUID is only supported on amd64")
+	}
+
+	maxLeaf, ebx, ecx, edx := cpuidFunc(0, 0)
+	if maxLeaf == 0 {
+		return nil, errors.New("CPUID leaf 1 is not supported")
+	}
+
+	info := &CPUIDInfo{
+		// the vendor string is stored in EBX, EDX, ECX order
+		Vendor:     string(registersToBytes(ebx, edx, ecx)),
+		Features:   []string{},
+		Caches:     []CPUIDCache{},
+		Topology:   []CPUIDTopologyLevel{},
+		Mismatches: []CPUIDMismatch{},
+	}
+	maxExtLeaf, _, _, _ := cpuidFunc(0x80000000, 0)
+
+	eax, _, _, _ := cpuidFunc(1, 0)
+	info.Family, info.Model, info.Stepping = decodeSignature(eax)
+
+	if maxExtLeaf >= 0x80000004 {
+		var brand []byte
+		for leaf := uint32(0x80000002); leaf <= 0x80000004; leaf++ {
+			eax, ebx, ecx, edx := cpuidFunc(leaf, 0)
+			brand = append(brand, registersToBytes(eax, ebx, ecx, edx)...)
+		}
+		info.Brand = strings.TrimSpace(strings.TrimRight(string(brand), "\x00"))
+	}
+
+	for _, feature := range cpuidFeatures {
+		if (feature.leaf < 0x80000000 && feature.leaf > maxLeaf) || (feature.leaf >= 0x80000000 && feature.leaf > maxExtLeaf) {
+			continue
+		}
+		_, ebx, ecx, edx := cpuidFunc(feature.leaf, 0)
+		reg := map[string]uint32{"ebx": ebx, "ecx": ecx, "edx": edx}[feature.reg]
+		if reg&(1<<feature.bit) != 0 {
+			info.Features = append(info.Features, feature.name)
+		}
+	}
+
+	// AMD reports its caches in leaf 0x8000001D, in the same format as leaf 4,
+	// when topology extensions are supported
+	if maxExtLeaf >= 0x8000001D && info.hasFeature("topoext") {
+		info.Caches = decodeCaches(0x8000001D)
+	} else if maxLeaf >= 4 {
+		info.Caches = decodeCaches(4)
+	}
+
+	// leaf 0x1F supersedes leaf 0xB, which it extends with module, tile and
+	// die levels
+	if maxLeaf >= 0x1F {
+		info.Topology = decodeTopology(0x1F)
+	}
+	if len(info.Topology) == 0 && maxLeaf >= 0xB {
+		info.Topology = decodeTopology(0xB)
+	}
+
+	return info, nil
+}
+
+// cpuidSignature returns the processor signature (EAX of leaf 1) of the
+// processor the current thread runs on, formatted as in /proc/cpuinfo, or an
+// empty string if CPUID is not available
+func cpuidSignature() string {
+	if cpuidFunc == nil {
+		return ""
+	}
+	if maxLeaf, _, _, _ := cpuidFunc(0, 0); maxLeaf == 0 {
+		return ""
+	}
+
+	// the processor type and reserved bits are dropped
+	eax, _, _, _ := cpuidFunc(1, 0)
+	return fmt.Sprintf("0x%08x", encodeSignature(decodeSignature(eax)))
+}
+
+// decodeSignature returns the display family, model and stepping from the
+// processor signature (EAX of leaf 1)
+func decodeSignature(eax uint32) (family, model, stepping uint32) {
+	stepping = eax & 0xf
+	family = (eax >> 8) & 0xf
+	model = (eax >> 4) & 0xf
+
+	// the extended model only applies to families 6 and 15, and the
+	// extended family to family 15
+	if family == 0x6 || family == 0xf {
+		model += ((eax >> 16) & 0xf) << 4
+	}
+	if family == 0xf {
+		family += (eax >> 20) & 0xff
+	}
+
+	return family, model, stepping
+}
+
+// encodeSignature returns the processor signature (EAX of leaf 1) matching
+// the given display family, model and stepping; it is the reverse of
+// decodeSignature
+func encodeSignature(family, model, stepping uint32) uint32 {
+	eax := stepping & 0xf
+	if family >= 0xf {
+		eax |= 0xf<<8 | ((family-0xf)&0xff)<<20
+	} else {
+		eax |= (family & 0xf) << 8
+	}
+	eax |= (model & 0xf) << 4
+	if family == 0x6 || family >= 0xf {
+		eax |= ((model >> 4) & 0xf) << 16
+	}
+	return eax
+}
+
+// decodeCaches enumerates the subleaves of the deterministic cache parameters
+// leaf (4 or 0x8000001D)
+func decodeCaches(leaf uint32) []CPUIDCache {
+	caches := []CPUIDCache{}
+	// the number of subleaves is not reported, but they end with a null type
+	for subleaf := uint32(0); subleaf < 64; subleaf++ {
+		eax, ebx, ecx, _ := cpuidFunc(leaf, subleaf)
+		cacheType, ok := cpuidCacheTypes[eax&0x1f]
+		if !ok {
+			break
+		}
+
+		ways := (ebx>>22)&0x3ff + 1
+		partitions := (ebx>>12)&0x3ff + 1
+		lineSize := ebx&0xfff + 1
+		sets := ecx + 1
+		caches = append(caches, CPUIDCache{
+			Level:         (eax >> 5) & 0x7,
+			Type:          cacheType,
+			SizeBytes:     uint64(ways) * uint64(partitions) * uint64(lineSize) * uint64(sets),
+			Associativity: ways,
+			LineSizeBytes: lineSize,
+			SharedThreads: (eax>>14)&0xfff + 1,
+		})
+	}
+	return caches
+}
+
+// decodeTopology enumerates the subleaves of an extended topology leaf (0xB
+// or 0x1F)
+func decodeTopology(leaf uint32) []CPUIDTopologyLevel {
+	levels := []CPUIDTopologyLevel{}
+	for subleaf := uint32(0); subleaf < 8; subleaf++ {
+		eax, ebx, ecx, _ := cpuidFunc(leaf, subleaf)
+		levelType := (ecx >> 8) & 0xff
+		if levelType == 0 || ebx&0xffff == 0 {
+			break
+		}
+
+		name, ok := cpuidTopologyTypes[levelType]
+		if !ok {
+			name = "Unknown"
+		}
+		levels = append(levels, CPUIDTopologyLevel{
+			Type:              name,
+			ShiftBits:         eax & 0x1f,
+			LogicalProcessors: ebx & 0xffff,
+		})
+	}
+	return levels
+}
+
+// hasFeature returns whether the given feature was reported by CPUID
+func (info *CPUIDInfo) hasFeature(name string) bool {
+	for _, feature := range info.Features {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// cpuid executes the CPUID instruction for the given leaf (eaxArg) and
+// subleaf (ecxArg), and is implemented in cpuid_amd64.s
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// cpuidFunc is the function used to query CPUID; it is replaced in tests
+var cpuidFunc = cpuid
@@ -0,0 +1,95 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cpuidCpuInfoFields maps the /proc/cpuinfo fields which can be decoded from
+// CPUID to the matching getCPUInfo entries
+var cpuidCpuInfoFields = []struct {
+	procField string
+	infoField string
+}{
+	{"vendor_id", "vendor_id"},
+	{"model name", "model_name"},
+	{"cpu family", "family"},
+	{"model", "model"},
+	{"stepping", "stepping"},
+}
+
+// cpuInfoValues returns the values of the cpuidCpuInfoFields, formatted as
+// in /proc/cpuinfo and keyed by /proc/cpuinfo field
+func (info *CPUIDInfo) cpuInfoValues() map[string]string {
+	return map[string]string{
+		"vendor_id":  info.Vendor,
+		"model name": info.Brand,
+		"cpu family": strconv.FormatUint(uint64(info.Family), 10),
+		"model":      strconv.FormatUint(uint64(info.Model), 10),
+		"stepping":   strconv.FormatUint(uint64(info.Stepping), 10),
+	}
+}
+
+// compareWithCpuInfo sets the Mismatches of info from the first processor
+// of /proc/cpuinfo.  A field missing from /proc/cpuinfo is a mismatch, as it
+// is a sign of the file being masked.
+func (info *CPUIDInfo) compareWithCpuInfo(procCpu []map[string]string) {
+	if len(procCpu) == 0 {
+		return
+	}
+
+	values := info.cpuInfoValues()
+	for _, field := range cpuidCpuInfoFields {
+		cpuidValue := values[field.procField]
+		procValue := procCpu[0][field.procField]
+		// the brand string is sometimes padded with extra spaces
+		if strings.Join(strings.Fields(cpuidValue), " ") != strings.Join(strings.Fields(procValue), " ") {
+			info.Mismatches = append(info.Mismatches, CPUIDMismatch{
+				Field:   field.procField,
+				CPUID:   cpuidValue,
+				CpuInfo: procValue,
+			})
+		}
+	}
+}
+
+// addCPUIDCpuInfo sets the identification entries of cpuInfo (vendor_id,
+// model_name, family, model and stepping) from CPUID.  Existing entries are
+// only replaced if override is set.  It returns false if CPUID is not
+// available.
+func addCPUIDCpuInfo(cpuInfo map[string]string, override bool) bool {
+	info, err := getCPUIDInfo()
+	if err != nil {
+		return false
+	}
+
+	values := info.cpuInfoValues()
+	for _, field := range cpuidCpuInfoFields {
+		if _, ok := cpuInfo[field.infoField]; ok && !override {
+			continue
+		}
+		if value := values[field.procField]; value != "" {
+			cpuInfo[field.infoField] = value
+		}
+	}
+	return true
+}
+
+// getCPUID returns the CPUID decoding, compared with /proc/cpuinfo when it
+// is readable
+func getCPUID() (*CPUIDInfo, error) {
+	info, err := getCPUIDInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if procCpu, err := readProcCpuInfo(); err == nil {
+		info.compareWithCpuInfo(procCpu)
+	}
+	return info, nil
+}
@@ -0,0 +1,137 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// intelLeaves returns the CPUID leaves of a Sapphire Rapids processor
+func intelLeaves() map[[2]uint32][4]uint32 {
+	vendor := stringRegisters("GenuineIntel")
+	brand := make([]byte, 48)
+	copy(brand, "Intel(R) Xeon(R) Platinum 8488C")
+	brandRegs := func(offset int) [4]uint32 {
+		var regs [4]uint32
+		for i := 0; i < 16; i++ {
+			regs[i/4] |= uint32(brand[offset+i]) << (8 * (i % 4))
+		}
+		return regs
+	}
+
+	return map[[2]uint32][4]uint32{
+		// max leaf, vendor in EBX, EDX, ECX
+		{0, 0}: {0x1f, vendor[0], vendor[2], vendor[1]},
+		// signature 0x000806f8: family 6, model 0x8f, stepping 8
+		{1, 0}: {0x000806f8, 0, 1<<0 | 1<<28 | 1<<31, 1<<0 | 1<<26},
+		{7, 0}: {0, 1<<5 | 1<<16, 0, 0},
+		// L1d: 48K, 12 ways, 64 sets, shared by 2 threads
+		{4, 0}: {1<<14 | 1<<5 | 1, 11<<22 | 63, 63, 0},
+		// L2: 2M, 16 ways, 2048 sets
+		{4, 1}: {1<<14 | 2<<5 | 3, 15<<22 | 63, 2047, 0},
+		// SMT level of 2 threads, core level of 112 threads
+		{0x1f, 0}:       {1, 2, 1 << 8, 0},
+		{0x1f, 1}:       {7, 112, 2<<8 | 1, 0},
+		{0x80000000, 0}: {0x80000008, 0, 0, 0},
+		{0x80000001, 0}: {0, 0, 1 << 0, 1<<11 | 1<<29},
+		{0x80000002, 0}: brandRegs(0),
+		{0x80000003, 0}: brandRegs(16),
+		{0x80000004, 0}: brandRegs(32),
+	}
+}
+
+func TestGetCPUIDInfo(t *testing.T) {
+	withCPUID(t, intelLeaves())
+
+	info, err := getCPUIDInfo()
+	require.NoError(t, err)
+	require.Equal(t, &CPUIDInfo{
+		Vendor:   "GenuineIntel",
+		Brand:    "Intel(R) Xeon(R) Platinum 8488C",
+		Family:   6,
+		Model:    0x8f,
+		Stepping: 8,
+		Features: []string{"fpu", "sse2", "pni", "avx", "hypervisor", "avx2", "avx512f", "lahf_lm", "syscall", "lm"},
+		Caches: []CPUIDCache{
+			{Level: 1, Type: "Data", SizeBytes: 48 * 1024, Associativity: 12, LineSizeBytes: 64, SharedThreads: 2},
+			{Level: 2, Type: "Unified", SizeBytes: 2 * 1024 * 1024, Associativity: 16, LineSizeBytes: 64, SharedThreads: 2},
+		},
+		Topology: []CPUIDTopologyLevel{
+			{Type: "SMT", ShiftBits: 1, LogicalProcessors: 2},
+			{Type: "Core", ShiftBits: 7, LogicalProcessors: 112},
+		},
+		Mismatches: []CPUIDMismatch{},
+	}, info)
+}
+
+func TestGetCPUIDInfoUnavailable(t *testing.T) {
+	withCPUID(t, nil)
+
+	_, err := getCPUIDInfo()
+	require.Error(t, err)
+	require.False(t, addCPUIDCpuInfo(map[string]string{}, true))
+}
+
+func TestDecodeSignature(t *testing.T) {
+	for _, tc := range []struct {
+		eax                     uint32
+		family, model, stepping uint32
+	}{
+		// Pentium 4: family 15, no extended family
+		{0x00000f29, 15, 2, 9},
+		// Zen 2: extended family 8, extended model 3
+		{0x00830f10, 0x17, 0x31, 0},
+		// Ice Lake: family 6 with extended model
+		{0x000606a6, 6, 0x6a, 6},
+		// family 5 ignores the extended model
+		{0x00010543, 5, 4, 3},
+	} {
+		family, model, stepping := decodeSignature(tc.eax)
+		require.Equal(t, [3]uint32{tc.family, tc.model, tc.stepping}, [3]uint32{family, model, stepping}, "signature %#x", tc.eax)
+	}
+}
+
+func TestCPUIDMismatches(t *testing.T) {
+	withCPUID(t, intelLeaves())
+	// LXCFS-like cpuinfo, missing the model name and reporting another model
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo": `processor	: 0
+vendor_id	: GenuineIntel
+cpu family	: 6
+model		: 85
+stepping	: 8
+`,
+	})
+
+	info, err := getCPUID()
+	require.NoError(t, err)
+	require.Equal(t, []CPUIDMismatch{
+		{Field: "model name", CPUID: "Intel(R) Xeon(R) Platinum 8488C", CpuInfo: ""},
+		{Field: "model", CPUID: "143", CpuInfo: "85"},
+	}, info.Mismatches)
+}
+
+func TestAddCPUIDCpuInfo(t *testing.T) {
+	withCPUID(t, intelLeaves())
+
+	cpuInfo := map[string]string{"vendor_id": "GenuineIntel", "model": "85"}
+	require.True(t, addCPUIDCpuInfo(cpuInfo, false))
+	require.Equal(t, map[string]string{
+		"vendor_id":  "GenuineIntel",
+		"model_name": "Intel(R) Xeon(R) Platinum 8488C",
+		"family":     "6",
+		"model":      "85",
+		"stepping":   "8",
+	}, cpuInfo)
+
+	require.True(t, addCPUIDCpuInfo(cpuInfo, true))
+	require.Equal(t, "143", cpuInfo["model"])
+}
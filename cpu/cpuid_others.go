@@ -0,0 +1,12 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build !amd64
+// +build !amd64
+
+package cpu
+
+// cpuidFunc is nil as CPUID is only queried on amd64; it is set in tests
+var cpuidFunc func(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
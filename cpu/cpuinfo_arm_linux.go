@@ -0,0 +1,87 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// The Linux kernel does not include much useful information in /proc/cpuinfo
+// for arm and arm64, so we must dig further into the /sys tree and build a more
+// accurate representation of the contained data, rather than relying on the
+// simple analysis in cpu/cpu_linux_default.go.
+
+// getARMCPUInfo implements getCPUInfo for arm and arm64
+func getARMCPUInfo() (cpuInfo map[string]string, err error) {
+	cpuInfo = make(map[string]string)
+
+	procCpu, err := readProcCpuInfo()
+	if err != nil {
+		return nil, err
+	}
+	if len(procCpu) == 0 {
+		return nil, errors.New("no processor found in /proc/cpuinfo")
+	}
+
+	// we blithely assume that many of the CPU characteristics are the same for
+	// all CPUs, so we can just use the first.
+	firstCpu := procCpu[0]
+
+	// determine vendor and model from CPU implementer / part
+	if cpuVariantStr, ok := firstCpu["CPU implementer"]; ok {
+		if cpuVariant, err := strconv.ParseUint(cpuVariantStr, 0, 64); err == nil {
+			if cpuPartStr, ok := firstCpu["CPU part"]; ok {
+				if cpuPart, err := strconv.ParseUint(cpuPartStr, 0, 64); err == nil {
+					cpuInfo["model"] = cpuPartStr
+					if impl, ok := hwVariant[cpuVariant]; ok {
+						cpuInfo["vendor_id"] = impl.name
+						if modelName, ok := impl.parts[cpuPart]; ok {
+							cpuInfo["model_name"] = modelName
+						} else {
+							cpuInfo["model_name"] = cpuPartStr
+						}
+					} else {
+						cpuInfo["vendor_id"] = cpuVariantStr
+						cpuInfo["model_name"] = cpuPartStr
+					}
+				}
+			}
+		}
+	}
+
+	// ARM does not define a family
+	cpuInfo["family"] = "none"
+
+	// 'lscpu' represents the stepping as an rXpY string
+	if cpuVariantStr, ok := firstCpu["CPU variant"]; ok {
+		if cpuVariant, err := strconv.ParseUint(cpuVariantStr, 0, 64); err == nil {
+			if cpuRevisionStr, ok := firstCpu["CPU revision"]; ok {
+				if cpuRevision, err := strconv.ParseUint(cpuRevisionStr, 0, 64); err == nil {
+					cpuInfo["stepping"] = fmt.Sprintf("r%dp%d", cpuVariant, cpuRevision)
+				}
+			}
+		}
+	}
+
+	// Fetch the topology and caches from /sys/devices/system/cpu
+	cores, packages := sysCpuTopology(procIDs(procCpu))
+	cpuInfo["cpu_pkgs"] = strconv.Itoa(packages)
+	cpuInfo["cpu_cores"] = strconv.Itoa(cores)
+	cpuInfo["cpu_logical_processors"] = strconv.Itoa(len(procCpu))
+
+	// the cache sizes are always reported, as 0 when sysfs reports no cache
+	// (as on many VMs)
+	caches, _ := getCaches()
+	setCacheSizes(cpuInfo, cacheSizesByLevel(caches))
+	addSysNumaNodes(cpuInfo)
+
+	// ARM does not make the clock speed available
+	// cpuInfo["mhz"]
+
+	return cpuInfo, nil
+}
@@ -0,0 +1,197 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// withProcCpuInfo sets up a fresh prefix containing the given fixture from
+// testdata/ as /proc/cpuinfo, or no /proc/cpuinfo at all if fixture is empty
+func withProcCpuInfo(t *testing.T, fixture string) {
+	utils.SetTestPrefix(t, &prefix)
+
+	if fixture == "" {
+		return
+	}
+	content, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	require.NoError(t, err)
+	utils.WriteTestFiles(t, prefix, map[string]string{"proc/cpuinfo": string(content)})
+}
+
+// withTopology writes the topology of each logical processor, given as
+// {package, core} pairs
+func withTopology(t *testing.T, topology [][2]int) {
+	for cpu, ids := range topology {
+		dir := fmt.Sprintf("sys/devices/system/cpu/cpu%d/topology/", cpu)
+		utils.WriteTestFiles(t, prefix, map[string]string{
+			dir + "physical_package_id": fmt.Sprintf("%d\n", ids[0]),
+			dir + "core_id":             fmt.Sprintf("%d\n", ids[1]),
+		})
+	}
+}
+
+func TestARMCPUInfo(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-arm-cortex-a53.txt")
+	withTopology(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}})
+
+	cpuInfo, err := getARMCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"vendor_id":              "ARM",
+		"model_name":             "Cortex-A53",
+		"model":                  "0xd03",
+		"family":                 "none",
+		"stepping":               "r0p4",
+		"cpu_pkgs":               "1",
+		"cpu_cores":              "4",
+		"cpu_logical_processors": "4",
+		"cpu_numa_nodes":         "0",
+		"cache_size":             "0 KB",
+		"cache_size_l1":          "0",
+		"cache_size_l2":          "0",
+		"cache_size_l3":          "0",
+	}, cpuInfo)
+}
+
+func TestARMCPUInfoMultiSocket(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-arm-cortex-a53.txt")
+	// two packages numbering their cores from 0: as in the legacy collector,
+	// cpu_cores counts the distinct core IDs
+	withTopology(t, [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}})
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "0", "1", "Data", "32K", "4", "0"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "1", "2", "Unified", "512K", "16", "0-1"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("2", "0", "1", "Data", "32K", "4", "2"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("2", "1", "2", "Unified", "512K", "16", "2-3"))
+
+	cpuInfo, err := getARMCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, "2", cpuInfo["cpu_pkgs"])
+	require.Equal(t, "2", cpuInfo["cpu_cores"])
+	require.Equal(t, "4", cpuInfo["cpu_logical_processors"])
+	require.Equal(t, "65536", cpuInfo["cache_size_l1"])
+	require.Equal(t, "1048576", cpuInfo["cache_size_l2"])
+	require.Equal(t, "0", cpuInfo["cache_size_l3"])
+	require.Equal(t, "1088 KB", cpuInfo["cache_size"])
+}
+
+func TestPOWERCPUInfo(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-ppc64le-power9.txt")
+	withTopology(t, [][2]int{{0, 0}, {0, 0}, {0, 0}, {0, 0}, {0, 4}, {0, 4}, {0, 4}, {0, 4}})
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "0", "1", "Data", "32K", "8", "0-3"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "1", "1", "Instruction", "32K", "8", "0-3"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "2", "2", "Unified", "512K", "8", "0-3"))
+	utils.WriteTestFiles(t, prefix, cacheFiles("0", "3", "3", "Unified", "10240K", "20", "0-3"))
+
+	cpuInfo, err := getPOWERCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"vendor_id":              "IBM",
+		"model_name":             "POWER9 (architected), altivec supported",
+		"model":                  "0x004e",
+		"family":                 "none",
+		"stepping":               "2.2",
+		"mhz":                    "2750.000",
+		"cpu_pkgs":               "1",
+		"cpu_cores":              "2",
+		"cpu_logical_processors": "8",
+		"cpu_numa_nodes":         "0",
+		"cache_size":             "10816 KB",
+		"cache_size_l1":          "65536",
+		"cache_size_l2":          "524288",
+		"cache_size_l3":          "10485760",
+	}, cpuInfo)
+}
+
+func TestS390XCPUInfo(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-s390x-z15.txt")
+
+	cpuInfo, err := getS390XCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"vendor_id":              "IBM/S390",
+		"model_name":             "IBM z15 T01",
+		"model":                  "8561",
+		"family":                 "none",
+		"mhz":                    "5200",
+		"cpu_pkgs":               "1",
+		"cpu_cores":              "2",
+		"cpu_logical_processors": "4",
+		"cpu_numa_nodes":         "0",
+	}, cpuInfo)
+}
+
+func TestRISCVCPUInfo(t *testing.T) {
+	t.Run("u74", func(t *testing.T) {
+		withProcCpuInfo(t, "cpuinfo-riscv64-u74.txt")
+		withTopology(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}})
+
+		cpuInfo, err := getRISCVCPUInfo()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"vendor_id":              "SiFive",
+			"model_name":             "sifive,u74-mc",
+			"model":                  "0x8000000000000007",
+			"family":                 "none",
+			"stepping":               "0x4210427",
+			"cpu_pkgs":               "1",
+			"cpu_cores":              "4",
+			"cpu_logical_processors": "4",
+			"cpu_numa_nodes":         "0",
+		}, cpuInfo)
+	})
+
+	t.Run("no ids", func(t *testing.T) {
+		withProcCpuInfo(t, "cpuinfo-riscv64-qemu.txt")
+
+		cpuInfo, err := getRISCVCPUInfo()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"model_name":             "rv64imafdcsu",
+			"family":                 "none",
+			"cpu_pkgs":               "0",
+			"cpu_cores":              "0",
+			"cpu_logical_processors": "2",
+			"cpu_numa_nodes":         "0",
+		}, cpuInfo)
+	})
+}
+
+func TestPOWERCPUInfoEmptyRevision(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-ppc64le-empty-revision.txt")
+
+	cpuInfo, err := getPOWERCPUInfo()
+	require.NoError(t, err)
+	require.NotContains(t, cpuInfo, "stepping")
+	require.NotContains(t, cpuInfo, "model")
+	require.Equal(t, "POWER8E (raw), altivec supported", cpuInfo["model_name"])
+	require.Equal(t, "2", cpuInfo["cpu_logical_processors"])
+}
+
+func TestS390XCPUInfoBooks(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	// the same socket and core IDs in two books
+	cpuinfo := "vendor_id       : IBM/S390\n# processors    : 2\n" +
+		"processor 0: version = FF,  identification = 0133E8,  machine = 8561\n" +
+		"processor 1: version = FF,  identification = 0133E8,  machine = 8561\n"
+	for cpu, book := range []string{"1", "2"} {
+		cpuinfo += fmt.Sprintf("\ncpu number      : %d\nphysical id     : 1\ncore id         : 0\nbook id         : %s\ndrawer id       : 4\nversion         : FF\nmachine         : 8561\n", cpu, book)
+	}
+	utils.WriteTestFiles(t, prefix, map[string]string{"proc/cpuinfo": cpuinfo})
+
+	cpuInfo, err := getS390XCPUInfo()
+	require.NoError(t, err)
+	require.Equal(t, "2", cpuInfo["cpu_pkgs"])
+	require.Equal(t, "2", cpuInfo["cpu_cores"])
+	require.NotContains(t, cpuInfo, "stepping")
+}
@@ -0,0 +1,106 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// On POWER, each processor stanza of /proc/cpuinfo only contains `cpu`,
+// `clock` and `revision`, eg:
+//
+//	processor	: 0
+//	cpu		: POWER9 (architected), altivec supported
+//	clock		: 2750.000000MHz
+//	revision	: 2.2 (pvr 004e 0202)
+//
+// followed by a trailing stanza describing the machine (`timebase`,
+// `platform`, `model`, `machine`, `MMU`).
+
+// pvrRegex extracts the version part of the processor version register from
+// the `revision` field
+var pvrRegex = regexp.MustCompile(`\(pvr ([0-9a-fA-F]{4}) [0-9a-fA-F]{4}\)`)
+
+// pvrVersions maps the version part of the processor version register to the
+// processor name, for kernels which do not report it in the `cpu` field
+var pvrVersions = map[uint64]string{
+	0x003f: "POWER7",
+	0x004a: "POWER7+",
+	0x004b: "POWER8E",
+	0x004c: "POWER8NVL",
+	0x004d: "POWER8",
+	0x004e: "POWER9",
+	0x0080: "POWER10",
+}
+
+// getPOWERCPUInfo implements getCPUInfo for ppc64 and ppc64le
+func getPOWERCPUInfo() (map[string]string, error) {
+	stanzas, err := readProcCpuInfoStanzas()
+	if err != nil {
+		return nil, err
+	}
+
+	var procCpu []map[string]string
+	for _, stanza := range stanzas {
+		if _, found := stanza["processor"]; found {
+			procCpu = append(procCpu, stanza)
+		}
+	}
+	if len(procCpu) == 0 {
+		return nil, errors.New("no processor found in /proc/cpuinfo")
+	}
+
+	cpuInfo := map[string]string{}
+	firstCpu := procCpu[0]
+
+	cpuInfo["vendor_id"] = "IBM"
+
+	// POWER does not define a family
+	cpuInfo["family"] = "none"
+
+	if revision, ok := firstCpu["revision"]; ok {
+		// 'lscpu' reports the whole field as the model, but the leading
+		// revision number is closer to what other platforms call a stepping
+		if fields := strings.Fields(revision); len(fields) > 0 {
+			cpuInfo["stepping"] = fields[0]
+		}
+
+		if submatches := pvrRegex.FindStringSubmatch(revision); submatches != nil {
+			cpuInfo["model"] = "0x" + submatches[1]
+			if version, err := strconv.ParseUint(submatches[1], 16, 64); err == nil {
+				if name, ok := pvrVersions[version]; ok {
+					cpuInfo["model_name"] = name
+				}
+			}
+		}
+	}
+
+	// the `cpu` field is more detailed than the PVR lookup, eg. including
+	// the compatibility mode of the LPAR
+	if cpuName, ok := firstCpu["cpu"]; ok {
+		cpuInfo["model_name"] = cpuName
+	}
+
+	// `clock` uses the format '2750.000000MHz'
+	if clock, ok := firstCpu["clock"]; ok {
+		if mhz, err := strconv.ParseFloat(strings.TrimSuffix(clock, "MHz"), 64); err == nil {
+			cpuInfo["mhz"] = fmt.Sprintf("%.3f", mhz)
+		}
+	}
+
+	cores, packages := sysCpuTopology(procIDs(procCpu))
+	cpuInfo["cpu_pkgs"] = strconv.Itoa(packages)
+	cpuInfo["cpu_cores"] = strconv.Itoa(cores)
+	cpuInfo["cpu_logical_processors"] = strconv.Itoa(len(procCpu))
+	addSysCacheSizes(cpuInfo)
+	addSysNumaNodes(cpuInfo)
+
+	return cpuInfo, nil
+}
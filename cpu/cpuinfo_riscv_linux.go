@@ -0,0 +1,89 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// On RISC-V, each processor stanza of /proc/cpuinfo describes the hart, eg:
+//
+//	processor	: 0
+//	hart		: 0
+//	isa		: rv64imafdc
+//	mmu		: sv39
+//	uarch		: sifive,u74-mc
+//	mvendorid	: 0x489
+//	marchid		: 0x8000000000000007
+//	mimpid		: 0x20181004
+//
+// `mvendorid`, `marchid` and `mimpid` were only added in Linux 6.1, and
+// `uarch` is only present when the device tree provides it.
+
+// riscvVendors maps the JEDEC manufacturer IDs found in `mvendorid` to the
+// vendor name
+var riscvVendors = map[uint64]string{
+	0x31e: "Andes",
+	0x489: "SiFive",
+	0x5b7: "T-Head",
+}
+
+// getRISCVCPUInfo implements getCPUInfo for riscv64
+func getRISCVCPUInfo() (map[string]string, error) {
+	procCpu, err := readProcCpuInfo()
+	if err != nil {
+		return nil, err
+	}
+	if len(procCpu) == 0 {
+		return nil, errors.New("no processor found in /proc/cpuinfo")
+	}
+
+	cpuInfo := map[string]string{}
+	firstCpu := procCpu[0]
+
+	// the vendor is taken from mvendorid, falling back to the vendor prefix
+	// of the device tree compatible string in `uarch`
+	if vendorStr, ok := firstCpu["mvendorid"]; ok {
+		cpuInfo["vendor_id"] = vendorStr
+		if vendor, err := strconv.ParseUint(vendorStr, 0, 64); err == nil {
+			if name, ok := riscvVendors[vendor]; ok {
+				cpuInfo["vendor_id"] = name
+			}
+		}
+	} else if uarch, ok := firstCpu["uarch"]; ok {
+		cpuInfo["vendor_id"] = strings.SplitN(uarch, ",", 2)[0]
+	}
+
+	if uarch, ok := firstCpu["uarch"]; ok {
+		cpuInfo["model_name"] = uarch
+	} else if isa, ok := firstCpu["isa"]; ok {
+		cpuInfo["model_name"] = isa
+	}
+
+	// RISC-V does not define a family
+	cpuInfo["family"] = "none"
+
+	if marchid, ok := firstCpu["marchid"]; ok {
+		cpuInfo["model"] = marchid
+	}
+	if mimpid, ok := firstCpu["mimpid"]; ok {
+		cpuInfo["stepping"] = mimpid
+	}
+
+	cores, packages := sysCpuTopology(procIDs(procCpu))
+	cpuInfo["cpu_pkgs"] = strconv.Itoa(packages)
+	cpuInfo["cpu_cores"] = strconv.Itoa(cores)
+	cpuInfo["cpu_logical_processors"] = strconv.Itoa(len(procCpu))
+	addSysCacheSizes(cpuInfo)
+	addSysNumaNodes(cpuInfo)
+
+	// RISC-V does not make the clock speed available
+	// cpuInfo["mhz"]
+
+	return cpuInfo, nil
+}
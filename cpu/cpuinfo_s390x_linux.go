@@ -0,0 +1,129 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// On s390x, /proc/cpuinfo starts with a stanza describing the whole machine,
+// including one `processor N` line per processor, eg:
+//
+//	vendor_id       : IBM/S390
+//	# processors    : 2
+//	...
+//	processor 0: version = FF,  identification = 0133E8,  machine = 8561
+//
+// followed by one stanza per processor, keyed by `cpu number`, with the
+// topology (`physical id`, `core id`), `machine` and `cpu MHz static`.
+
+// s390xProcessorRegex recognizes the `processor N` lines of the first stanza
+var s390xProcessorRegex = regexp.MustCompile(`^processor [0-9]+$`)
+
+// s390xMachineRegex extracts the machine type from a `processor N` line
+var s390xMachineRegex = regexp.MustCompile(`machine = ([0-9A-Fa-f]+)`)
+
+// s390xMachines maps IBM Z machine types to model names
+var s390xMachines = map[string]string{
+	"2094": "IBM System z9 EC",
+	"2096": "IBM System z9 BC",
+	"2097": "IBM System z10 EC",
+	"2098": "IBM System z10 BC",
+	"2817": "IBM zEnterprise 196",
+	"2818": "IBM zEnterprise 114",
+	"2827": "IBM zEnterprise EC12",
+	"2828": "IBM zEnterprise BC12",
+	"2964": "IBM z13",
+	"2965": "IBM z13s",
+	"3906": "IBM z14",
+	"3907": "IBM z14 ZR1",
+	"8561": "IBM z15 T01",
+	"8562": "IBM z15 T02",
+	"3931": "IBM z16 A01",
+	"3932": "IBM z16 A02",
+}
+
+// getS390XCPUInfo implements getCPUInfo for s390x
+func getS390XCPUInfo() (map[string]string, error) {
+	stanzas, err := readProcCpuInfoStanzas()
+	if err != nil {
+		return nil, err
+	}
+
+	var machine map[string]string
+	var procCpu []map[string]string
+	for _, stanza := range stanzas {
+		if _, found := stanza["vendor_id"]; found && machine == nil {
+			machine = stanza
+		}
+		if _, found := stanza["cpu number"]; found {
+			procCpu = append(procCpu, stanza)
+		}
+	}
+	if machine == nil {
+		return nil, errors.New("no machine description found in /proc/cpuinfo")
+	}
+
+	cpuInfo := map[string]string{}
+	cpuInfo["vendor_id"] = machine["vendor_id"]
+
+	// IBM Z does not define a family
+	cpuInfo["family"] = "none"
+
+	// per-processor stanzas are only present on recent kernels, otherwise
+	// the `processor N` lines are all we have
+	logical := 0
+	for key, value := range machine {
+		if !s390xProcessorRegex.MatchString(key) {
+			continue
+		}
+		logical++
+		if submatches := s390xMachineRegex.FindStringSubmatch(value); submatches != nil {
+			cpuInfo["model"] = submatches[1]
+		}
+	}
+	if n, ok := machine["# processors"]; ok {
+		cpuInfo["cpu_logical_processors"] = n
+	} else {
+		cpuInfo["cpu_logical_processors"] = strconv.Itoa(logical)
+	}
+
+	// the socket (`physical id`) and core IDs are only unique within their
+	// book and drawer, so the packages and cores are keyed by the whole
+	// drawer/book/socket/core topology
+	cores := map[[4]string]struct{}{}
+	packages := map[[3]string]struct{}{}
+	for _, stanza := range procCpu {
+		socket := [3]string{stanza["drawer id"], stanza["book id"], stanza["physical id"]}
+		packages[socket] = struct{}{}
+		cores[[4]string{socket[0], socket[1], socket[2], stanza["core id"]}] = struct{}{}
+	}
+	if len(procCpu) != 0 {
+		firstCpu := procCpu[0]
+		if m, ok := firstCpu["machine"]; ok {
+			cpuInfo["model"] = m
+		}
+		// `version` is not a stepping, as it is usually FF, so none is set
+		if mhz, ok := firstCpu["cpu MHz static"]; ok {
+			cpuInfo["mhz"] = mhz
+		}
+		cpuInfo["cpu_pkgs"] = strconv.Itoa(len(packages))
+		cpuInfo["cpu_cores"] = strconv.Itoa(len(cores))
+	}
+
+	if name, ok := s390xMachines[cpuInfo["model"]]; ok {
+		cpuInfo["model_name"] = name
+	} else {
+		cpuInfo["model_name"] = cpuInfo["model"]
+	}
+
+	addSysCacheSizes(cpuInfo)
+	addSysNumaNodes(cpuInfo)
+
+	return cpuInfo, nil
+}
@@ -0,0 +1,47 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import "fmt"
+
+// getCPUDetails fills the structured, Linux-only fields of the given Cpu.
+// Failures are reported as warnings, leaving the matching fields empty.
+func getCPUDetails(c *Cpu, warnings *[]string) {
+	if caches, err := getCaches(); err == nil {
+		c.Caches = caches
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect cache details: %s", err))
+	}
+
+	c.Virtualization = getVirtualization()
+
+	if effective, err := GetEffectiveCPUs(0); err == nil {
+		c.EffectiveCPUs = effective
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect effective CPUs: %s", err))
+	}
+
+	if nodes, err := getNumaNodes(); err == nil {
+		c.NumaNodes = nodes
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect NUMA nodes: %s", err))
+	}
+
+	// CPUID is only available on amd64, which is not worth a warning elsewhere
+	if cpuidFunc != nil {
+		if cpuid, err := getCPUID(); err == nil {
+			c.CPUID = cpuid
+		} else {
+			*warnings = append(*warnings, fmt.Sprintf("could not decode CPUID: %s", err))
+		}
+	}
+
+	if sockets, err := getSockets(); err == nil {
+		c.Sockets = sockets
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect socket identification: %s", err))
+	}
+}
@@ -0,0 +1,33 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package cpu
+
+import (
+	"errors"
+	"time"
+)
+
+// getCPUDetails is a no-op: the structured details are only collected on Linux
+func getCPUDetails(c *Cpu, warnings *[]string) {}
+
+// SampleUtilization returns an error: CPU utilization is only sampled on Linux
+func SampleUtilization(window time.Duration) (*Utilization, error) {
+	return nil, errors.New("CPU utilization sampling is only supported on Linux")
+}
+
+// GetEffectiveCPUs returns an error: cgroups only exist on Linux
+func GetEffectiveCPUs(pid int) (*EffectiveCPUs, error) {
+	return nil, errors.New("cgroups are only supported on Linux")
+}
+
+// GetPower returns an error: power and thermal details are only read from
+// the Linux sysfs
+func GetPower() (*Power, error) {
+	return nil, errors.New("CPU power details are only supported on Linux")
+}
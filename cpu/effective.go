@@ -0,0 +1,32 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// EffectiveCPUs describes the CPUs actually available to a process once the
+// CFS quota and cpuset of its cgroup are applied, as returned by
+// GetEffectiveCPUs
+type EffectiveCPUs struct {
+	// CgroupVersion is the version of the cgroup hierarchy the limits were
+	// read from (1 or 2)
+	CgroupVersion int `json:"cgroup_version"`
+	// CgroupPath is the cgroup of the process, relative to the hierarchy root
+	CgroupPath string `json:"cgroup_path"`
+	// QuotaMicroseconds is the CPU time the cgroup may use in each period,
+	// or -1 if it is unlimited
+	QuotaMicroseconds int64 `json:"quota_us"`
+	// PeriodMicroseconds is the length of the quota period
+	PeriodMicroseconds uint64 `json:"period_us"`
+	// Cpuset is the list of processors the cgroup may run on (eg. "0-3,8")
+	Cpuset string `json:"cpuset"`
+	// CpusetCount is the number of processors in Cpuset
+	CpusetCount uint64 `json:"cpuset_count"`
+	// HostLogicalProcessors is the number of online logical processors of
+	// the host
+	HostLogicalProcessors uint64 `json:"host_logical_processors"`
+	// Count is the effective number of CPUs: the smallest of the quota (as a
+	// fraction of the period), the cpuset and the host processors
+	Count float64 `json:"count"`
+}
@@ -0,0 +1,135 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// GetEffectiveCPUs returns the CPUs available to the process with the given
+// PID (the current process if pid is 0), based on its cgroup:
+//   - v1: cpu.cfs_quota_us and cpu.cfs_period_us from the cpu hierarchy,
+//     cpuset.effective_cpus (or cpuset.cpus) from the cpuset hierarchy
+//   - v2: cpu.max and cpuset.cpus.effective
+//
+// Quotas are checked in every ancestor of the cgroup, as they also apply to
+// descendants, and the most restrictive one is reported.
+func GetEffectiveCPUs(pid int) (*EffectiveCPUs, error) {
+	cgroups, err := utils.GetCgroups(prefix, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &EffectiveCPUs{QuotaMicroseconds: -1}
+
+	if online, ok := sysCpuList("online"); ok {
+		e.HostLogicalProcessors = uint64(len(online))
+	} else if ids, err := sysCpuIDs(); err == nil {
+		e.HostLogicalProcessors = uint64(len(ids))
+	}
+	e.Count = float64(e.HostLogicalProcessors)
+
+	cpuCgroup := utils.FindCgroup(cgroups, "cpu")
+	if cpuCgroup == nil {
+		return nil, errors.New("no cgroup hierarchy found for the cpu controller")
+	}
+	e.CgroupVersion = cpuCgroup.Version
+	e.CgroupPath = cpuCgroup.Path
+
+	for _, dir := range cpuCgroup.Ancestors() {
+		quota, period, ok := readCFSQuota(cpuCgroup.Version, dir)
+		if !ok || period == 0 {
+			continue
+		}
+		if quota < 0 {
+			// report the period of the closest cgroup even without a quota
+			if e.PeriodMicroseconds == 0 {
+				e.PeriodMicroseconds = period
+			}
+			continue
+		}
+		if e.QuotaMicroseconds < 0 || float64(quota)/float64(period) < float64(e.QuotaMicroseconds)/float64(e.PeriodMicroseconds) {
+			e.QuotaMicroseconds = quota
+			e.PeriodMicroseconds = period
+		}
+	}
+	if e.QuotaMicroseconds >= 0 {
+		if count := float64(e.QuotaMicroseconds) / float64(e.PeriodMicroseconds); count < e.Count {
+			e.Count = count
+		}
+	}
+
+	if cpusetCgroup := utils.FindCgroup(cgroups, "cpuset"); cpusetCgroup != nil {
+		files := []string{"cpuset.cpus.effective"}
+		if cpusetCgroup.Version == 1 {
+			files = []string{"cpuset.effective_cpus", "cpuset.cpus"}
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(filepath.Join(cpusetCgroup.Dir, file))
+			if err != nil {
+				continue
+			}
+			if cpus, ok := parseCpuList(string(content)); ok && len(cpus) != 0 {
+				e.Cpuset = strings.TrimSpace(string(content))
+				e.CpusetCount = uint64(len(cpus))
+				if float64(e.CpusetCount) < e.Count {
+					e.Count = float64(e.CpusetCount)
+				}
+				break
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// readCFSQuota reads the CFS quota and period of the cgroup in dir.  The
+// quota is -1 when the cgroup is not limited.
+func readCFSQuota(version int, dir string) (quota int64, period uint64, ok bool) {
+	if version == 2 {
+		// cpu.max uses the format `$MAX $PERIOD`, with `max` for no limit
+		content, err := ioutil.ReadFile(filepath.Join(dir, "cpu.max"))
+		if err != nil {
+			return 0, 0, false
+		}
+		fields := strings.Fields(string(content))
+		if len(fields) != 2 {
+			return 0, 0, false
+		}
+		if period, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return 0, 0, false
+		}
+		if fields[0] == "max" {
+			return -1, period, true
+		}
+		if quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return 0, 0, false
+		}
+		return quota, period, true
+	}
+
+	quotaContent, err := ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, 0, false
+	}
+	periodContent, err := ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, 0, false
+	}
+	if quota, err = strconv.ParseInt(strings.TrimSpace(string(quotaContent)), 10, 64); err != nil {
+		return 0, 0, false
+	}
+	if period, err = strconv.ParseUint(strings.TrimSpace(string(periodContent)), 10, 64); err != nil {
+		return 0, 0, false
+	}
+	return quota, period, true
+}
@@ -0,0 +1,94 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestEffectiveCPUsV1(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/devices/system/cpu/online": "0-7\n",
+		"proc/self/cgroup": `5:cpuset:/docker/abcd
+3:cpu,cpuacct:/docker/abcd
+0::/
+`,
+		"proc/self/mountinfo": `30 25 0:26 / /sys/fs/cgroup/cpuset rw - cgroup cgroup rw,cpuset
+31 25 0:27 / /sys/fs/cgroup/cpu,cpuacct rw - cgroup cgroup rw,cpu,cpuacct
+`,
+		"sys/fs/cgroup/cpu,cpuacct/docker/abcd/cpu.cfs_quota_us":  "150000\n",
+		"sys/fs/cgroup/cpu,cpuacct/docker/abcd/cpu.cfs_period_us": "100000\n",
+		"sys/fs/cgroup/cpu,cpuacct/docker/cpu.cfs_quota_us":       "-1\n",
+		"sys/fs/cgroup/cpu,cpuacct/docker/cpu.cfs_period_us":      "100000\n",
+		"sys/fs/cgroup/cpuset/docker/abcd/cpuset.effective_cpus":  "0-3\n",
+	})
+
+	effective, err := GetEffectiveCPUs(0)
+	require.NoError(t, err)
+	require.Equal(t, &EffectiveCPUs{
+		CgroupVersion:         1,
+		CgroupPath:            "/docker/abcd",
+		QuotaMicroseconds:     150000,
+		PeriodMicroseconds:    100000,
+		Cpuset:                "0-3",
+		CpusetCount:           4,
+		HostLogicalProcessors: 8,
+		Count:                 1.5,
+	}, effective)
+}
+
+func TestEffectiveCPUsV2(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/devices/system/cpu/online": "0-15\n",
+		"proc/self/cgroup":              "0::/kubepods.slice/pod1.slice/container.scope\n",
+		"proc/self/mountinfo":           "35 24 0:30 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n",
+		// the limit of the pod applies to the container
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/cpu.max":               "max 100000\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/cpu.max":                               "250000 100000\n",
+		"sys/fs/cgroup/kubepods.slice/cpu.max":                                          "1200000 100000\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/cpuset.cpus.effective": "0-3,8-11\n",
+	})
+
+	effective, err := GetEffectiveCPUs(0)
+	require.NoError(t, err)
+	require.Equal(t, &EffectiveCPUs{
+		CgroupVersion:         2,
+		CgroupPath:            "/kubepods.slice/pod1.slice/container.scope",
+		QuotaMicroseconds:     250000,
+		PeriodMicroseconds:    100000,
+		Cpuset:                "0-3,8-11",
+		CpusetCount:           8,
+		HostLogicalProcessors: 16,
+		Count:                 2.5,
+	}, effective)
+}
+
+func TestEffectiveCPUsUnlimited(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/devices/system/cpu/online":    "0-3\n",
+		"proc/self/cgroup":                 "0::/user.slice\n",
+		"proc/self/mountinfo":              "35 24 0:30 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n",
+		"sys/fs/cgroup/user.slice/cpu.max": "max 100000\n",
+	})
+
+	effective, err := GetEffectiveCPUs(0)
+	require.NoError(t, err)
+	require.Equal(t, &EffectiveCPUs{
+		CgroupVersion:         2,
+		CgroupPath:            "/user.slice",
+		QuotaMicroseconds:     -1,
+		PeriodMicroseconds:    100000,
+		HostLogicalProcessors: 4,
+		Count:                 4,
+	}, effective)
+}
@@ -3,7 +3,7 @@
 import sys, re, textwrap
 
 # This script reads a copy of lscpu-arm.c and outputs the various tables to be
-# included in cpu_linux_arm.go.  Of course, this may need adjustment as other
+# included in lscpu_arm_linux.go.  Of course, this may need adjustment as other
 # changes are made to lscpu-arm.c.
 
 def main():
@@ -30,8 +30,8 @@ def main():
     print(textwrap.dedent("""\
         // Code generated by cpu/from-lscpu-arm.py; DO NOT EDIT.
 
+        //go:build linux
         // +build linux
-        // +build arm64
 
         package cpu
 
@@ -0,0 +1,61 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// SocketInfo identifies the processors of a socket (physical package), as
+// needed to track CPU errata and microcode updates across a fleet
+type SocketInfo struct {
+	// Socket is the physical package ID
+	Socket uint64 `json:"socket"`
+	// CPUs lists the logical processors of the socket
+	CPUs []uint64 `json:"cpus"`
+	// Microcode is the microcode revision of the first processor of the
+	// socket (eg. "0x2b000590"), x86 only
+	Microcode string `json:"microcode,omitempty"`
+	// MixedMicrocode is set if the processors of the socket do not all run
+	// the same microcode revision, eg. after a failed late load
+	MixedMicrocode bool `json:"mixed_microcode"`
+	// Signature is the processor signature (CPUID leaf 1 EAX, eg.
+	// "0x000806f8"), which microcode updates are matched against, x86 only
+	Signature string `json:"signature,omitempty"`
+	// ProcessorFlags is the mask of the platform ID as exposed by the
+	// microcode driver (eg. "0x80"), Intel only
+	ProcessorFlags string `json:"processor_flags,omitempty"`
+	// PlatformID is the platform ID (bits 52:50 of MSR IA32_PLATFORM_ID),
+	// derived from ProcessorFlags, Intel only
+	PlatformID *uint64 `json:"platform_id,omitempty"`
+	// ARM lists the distinct identification registers of the processors of
+	// the socket, which differ between cluster types on big.LITTLE systems
+	ARM []ARMIdentification `json:"arm,omitempty"`
+}
+
+// ARMIdentification is the decoding of the MIDR_EL1 register of arm and
+// arm64 processors, along with REVIDR_EL1
+type ARMIdentification struct {
+	// MIDR is the raw MIDR_EL1 value (eg. "0x00000000410fd083"), empty if
+	// it is only known from /proc/cpuinfo
+	MIDR string `json:"midr_el1,omitempty"`
+	// REVIDR is the raw REVIDR_EL1 value, which flags implementation
+	// specific fixes on top of the revision
+	REVIDR string `json:"revidr_el1,omitempty"`
+	// Implementer is the implementer code (eg. 0x41 for ARM)
+	Implementer uint64 `json:"implementer"`
+	// Vendor is the name of the implementer
+	Vendor string `json:"vendor,omitempty"`
+	// Variant is the major revision number (the X in rXpY)
+	Variant uint64 `json:"variant"`
+	// Architecture is the architecture code (0xf when defined by the ID
+	// registers)
+	Architecture uint64 `json:"architecture"`
+	// PartNum is the primary part number (eg. 0xd03)
+	PartNum uint64 `json:"part_num"`
+	// ModelName is the name of the part (eg. "Cortex-A53")
+	ModelName string `json:"model_name,omitempty"`
+	// Revision is the minor revision number (the Y in rXpY)
+	Revision uint64 `json:"revision"`
+	// CPUs lists the logical processors with this identification
+	CPUs []uint64 `json:"cpus"`
+}
@@ -0,0 +1,176 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// getSockets groups the logical processors by physical package, and returns
+// the microcode and identification of each package from
+// /sys/devices/system/cpu/cpuN and /proc/cpuinfo
+func getSockets() ([]SocketInfo, error) {
+	procCpu, procErr := readProcCpuInfo()
+	stanzas := map[uint64]map[string]string{}
+	for _, stanza := range procCpu {
+		if procID, err := strconv.ParseUint(stanza["processor"], 0, 64); err == nil {
+			stanzas[procID] = stanza
+		}
+	}
+
+	ids, err := sysCpuIDs()
+	if err != nil || len(ids) == 0 {
+		ids = procIDs(procCpu)
+	}
+	if len(ids) == 0 {
+		if procErr != nil {
+			return nil, procErr
+		}
+		return nil, errors.New("no processor found")
+	}
+
+	sockets := map[uint64]*SocketInfo{}
+	for _, procID := range ids {
+		stanza := stanzas[procID]
+
+		socketID, ok := sysCpuInt(fmt.Sprintf("cpu%d/topology/physical_package_id", procID))
+		if !ok {
+			socketID, _ = strconv.ParseUint(stanza["physical id"], 10, 64)
+		}
+		socket, ok := sockets[socketID]
+		if !ok {
+			socket = &SocketInfo{Socket: socketID, CPUs: []uint64{}}
+			sockets[socketID] = socket
+		}
+		socket.CPUs = append(socket.CPUs, procID)
+
+		// the microcode driver exposes the revision in sysfs, and the kernel
+		// also reports it in /proc/cpuinfo on x86
+		microcode, ok := sysCpuString(fmt.Sprintf("cpu%d/microcode/version", procID))
+		if !ok {
+			microcode = stanza["microcode"]
+		}
+		if microcode != "" {
+			if socket.Microcode == "" {
+				socket.Microcode = microcode
+			} else if socket.Microcode != microcode {
+				socket.MixedMicrocode = true
+			}
+		}
+
+		if socket.Signature == "" {
+			socket.Signature = cpuInfoSignature(stanza)
+		}
+
+		if socket.ProcessorFlags == "" {
+			if flags, ok := sysCpuString(fmt.Sprintf("cpu%d/microcode/processor_flags", procID)); ok {
+				socket.ProcessorFlags = flags
+				// the flags are a mask with the bit of the platform ID set
+				if mask, err := strconv.ParseUint(flags, 0, 64); err == nil && bits.OnesCount64(mask) == 1 {
+					platformID := uint64(bits.TrailingZeros64(mask))
+					socket.PlatformID = &platformID
+				}
+			}
+		}
+
+		if ident, ok := armIdentification(procID, stanza); ok {
+			socket.ARM = addARMIdentification(socket.ARM, ident)
+		}
+	}
+
+	result := make([]SocketInfo, 0, len(sockets))
+	for _, socket := range sockets {
+		// /proc/cpuinfo may be masked, eg. in some sandboxes: fall back to
+		// CPUID, assuming all the x86 sockets are identical
+		if socket.Signature == "" && socket.ARM == nil {
+			socket.Signature = cpuidSignature()
+		}
+		result = append(result, *socket)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Socket < result[j].Socket })
+
+	return result, nil
+}
+
+// cpuInfoSignature returns the processor signature of an x86 processor from
+// its /proc/cpuinfo stanza, or an empty string on other architectures
+func cpuInfoSignature(stanza map[string]string) string {
+	if stanza["vendor_id"] == "" {
+		return ""
+	}
+
+	var values [3]uint32
+	for i, field := range []string{"cpu family", "model", "stepping"} {
+		value, err := strconv.ParseUint(stanza[field], 10, 32)
+		if err != nil {
+			return ""
+		}
+		values[i] = uint32(value)
+	}
+
+	return fmt.Sprintf("0x%08x", encodeSignature(values[0], values[1], values[2]))
+}
+
+// armIdentification returns the identification of an arm or arm64 processor
+// from /sys/devices/system/cpu/cpuN/regs/identification, falling back to the
+// fields of its /proc/cpuinfo stanza (which lack the architecture code and
+// REVIDR)
+func armIdentification(procID uint64, stanza map[string]string) (ARMIdentification, bool) {
+	ident := ARMIdentification{CPUs: []uint64{procID}}
+
+	dir := fmt.Sprintf("cpu%d/regs/identification/", procID)
+	if midrStr, ok := sysCpuString(dir + "midr_el1"); ok {
+		midr, err := strconv.ParseUint(midrStr, 0, 64)
+		if err != nil {
+			return ident, false
+		}
+		ident.MIDR = midrStr
+		ident.REVIDR, _ = sysCpuString(dir + "revidr_el1")
+		// MIDR_EL1 is laid out as implementer[31:24] variant[23:20]
+		// architecture[19:16] part[15:4] revision[3:0]
+		ident.Implementer = (midr >> 24) & 0xff
+		ident.Variant = (midr >> 20) & 0xf
+		ident.Architecture = (midr >> 16) & 0xf
+		ident.PartNum = (midr >> 4) & 0xfff
+		ident.Revision = midr & 0xf
+	} else {
+		var err error
+		if ident.Implementer, err = strconv.ParseUint(stanza["CPU implementer"], 0, 64); err != nil {
+			return ident, false
+		}
+		if ident.PartNum, err = strconv.ParseUint(stanza["CPU part"], 0, 64); err != nil {
+			return ident, false
+		}
+		ident.Variant, _ = strconv.ParseUint(stanza["CPU variant"], 0, 64)
+		ident.Revision, _ = strconv.ParseUint(stanza["CPU revision"], 0, 64)
+	}
+
+	if impl, ok := hwVariant[ident.Implementer]; ok {
+		ident.Vendor = impl.name
+		ident.ModelName = impl.parts[ident.PartNum]
+	}
+
+	return ident, true
+}
+
+// addARMIdentification adds the processors of ident to the matching entry of
+// idents, or appends ident if there is none
+func addARMIdentification(idents []ARMIdentification, ident ARMIdentification) []ARMIdentification {
+	for i := range idents {
+		existing := &idents[i]
+		if existing.MIDR == ident.MIDR && existing.REVIDR == ident.REVIDR &&
+			existing.Implementer == ident.Implementer && existing.Variant == ident.Variant &&
+			existing.PartNum == ident.PartNum && existing.Revision == ident.Revision {
+			existing.CPUs = append(existing.CPUs, ident.CPUs...)
+			return idents
+		}
+	}
+	return append(idents, ident)
+}
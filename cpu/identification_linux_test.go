@@ -0,0 +1,148 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetSocketsX86(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	var procCpuInfo string
+	for cpu, microcode := range []string{"0x2b000590", "0x2b000590", "0x2b000590", "0x2b000571"} {
+		procCpuInfo += fmt.Sprintf(`processor	: %d
+vendor_id	: GenuineIntel
+cpu family	: 6
+model		: 143
+stepping	: 8
+microcode	: %s
+physical id	: %d
+
+`, cpu, microcode, cpu/2)
+	}
+	utils.WriteTestFiles(t, prefix, map[string]string{"proc/cpuinfo": procCpuInfo})
+	withTopology(t, [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}})
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/devices/system/cpu/cpu0/microcode/version":         "0x2b000590\n",
+		"sys/devices/system/cpu/cpu0/microcode/processor_flags": "0x80\n",
+	})
+
+	sockets, err := getSockets()
+	require.NoError(t, err)
+	platformID := uint64(7)
+	require.Equal(t, []SocketInfo{
+		{
+			Socket:         0,
+			CPUs:           []uint64{0, 1},
+			Microcode:      "0x2b000590",
+			Signature:      "0x000806f8",
+			ProcessorFlags: "0x80",
+			PlatformID:     &platformID,
+		},
+		{
+			Socket:         1,
+			CPUs:           []uint64{2, 3},
+			Microcode:      "0x2b000590",
+			MixedMicrocode: true,
+			Signature:      "0x000806f8",
+		},
+	}, sockets)
+}
+
+func TestGetSocketsMaskedCpuInfo(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	// the identification fields are missing from /proc/cpuinfo
+	utils.WriteTestFiles(t, prefix, map[string]string{"proc/cpuinfo": "processor\t: 0\n\nprocessor\t: 1\n\n"})
+	withTopology(t, [][2]int{{0, 0}, {1, 0}})
+	withCPUID(t, map[[2]uint32][4]uint32{
+		{0, 0}: {0x20},
+		// the processor type bits are set
+		{1, 0}: {0x000836f8},
+	})
+
+	sockets, err := getSockets()
+	require.NoError(t, err)
+	require.Equal(t, []SocketInfo{
+		{Socket: 0, CPUs: []uint64{0}, Signature: "0x000806f8"},
+		{Socket: 1, CPUs: []uint64{1}, Signature: "0x000806f8"},
+	}, sockets)
+
+	// without CPUID, the signature is left empty
+	withCPUID(t, nil)
+	sockets, err = getSockets()
+	require.NoError(t, err)
+	require.Empty(t, sockets[0].Signature)
+}
+
+func TestGetSocketsARM(t *testing.T) {
+	withProcCpuInfo(t, "cpuinfo-arm-cortex-a53.txt")
+	withTopology(t, [][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}})
+	// a big.LITTLE system: cpu2 has no identification registers in sysfs and
+	// falls back to /proc/cpuinfo
+	for cpu, midr := range map[int]string{0: "0x00000000411fd050", 1: "0x00000000411fd050", 3: "0x00000000414fd0b1"} {
+		dir := fmt.Sprintf("sys/devices/system/cpu/cpu%d/regs/identification/", cpu)
+		utils.WriteTestFiles(t, prefix, map[string]string{
+			dir + "midr_el1":   midr + "\n",
+			dir + "revidr_el1": "0x0000000000000000\n",
+		})
+	}
+
+	sockets, err := getSockets()
+	require.NoError(t, err)
+	require.Equal(t, []SocketInfo{
+		{
+			Socket: 0,
+			CPUs:   []uint64{0, 1, 2, 3},
+			ARM: []ARMIdentification{
+				{
+					MIDR:         "0x00000000411fd050",
+					REVIDR:       "0x0000000000000000",
+					Implementer:  0x41,
+					Vendor:       "ARM",
+					Variant:      1,
+					Architecture: 0xf,
+					PartNum:      0xd05,
+					ModelName:    "Cortex-A55",
+					Revision:     0,
+					CPUs:         []uint64{0, 1},
+				},
+				{
+					Implementer: 0x41,
+					Vendor:      "ARM",
+					PartNum:     0xd03,
+					ModelName:   "Cortex-A53",
+					Revision:    4,
+					CPUs:        []uint64{2},
+				},
+				{
+					MIDR:         "0x00000000414fd0b1",
+					REVIDR:       "0x0000000000000000",
+					Implementer:  0x41,
+					Vendor:       "ARM",
+					Variant:      4,
+					Architecture: 0xf,
+					PartNum:      0xd0b,
+					ModelName:    "Cortex-A76",
+					Revision:     1,
+					CPUs:         []uint64{3},
+				},
+			},
+		},
+	}, sockets)
+}
+
+func TestEncodeSignature(t *testing.T) {
+	for _, eax := range []uint32{0x00000f29, 0x00830f10, 0x000606a6, 0x00000543, 0x00a20f12} {
+		require.Equal(t, eax, encodeSignature(decodeSignature(eax)), "signature %#x", eax)
+	}
+}
@@ -1,7 +1,7 @@
 // Code generated by cpu/from-lscpu-arm.py; DO NOT EDIT.
 
-//go:build linux && arm64
-// +build linux,arm64
+//go:build linux
+// +build linux
 
 package cpu
 
@@ -0,0 +1,25 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// NumaNode describes a NUMA node: the processors, memory and PCI devices
+// attached to it, and its distance to every node
+type NumaNode struct {
+	// ID is the node number
+	ID uint64 `json:"id"`
+	// CPUs lists the logical processors of the node
+	CPUs []uint64 `json:"cpus"`
+	// MemTotalBytes is the memory attached to the node
+	MemTotalBytes uint64 `json:"mem_total_bytes"`
+	// MemFreeBytes is the unused memory of the node
+	MemFreeBytes uint64 `json:"mem_free_bytes"`
+	// PCIDevices lists the addresses of the PCI devices attached to the
+	// node (eg. "0000:3b:00.0")
+	PCIDevices []string `json:"pci_devices"`
+	// Distances is the relative distance from this node to each node, in the
+	// order of their IDs (10 being the distance of a node to itself)
+	Distances []uint64 `json:"distances"`
+}
@@ -0,0 +1,160 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nodeNRegex recognizes directories named `nodeNN`
+var nodeNRegex = regexp.MustCompile("^node([0-9]+)$")
+
+// sysNodeIDs returns the sorted IDs of the NUMA nodes present in
+// /sys/devices/system/node
+func sysNodeIDs() ([]uint64, error) {
+	dirents, err := os.ReadDir(prefix + "/sys/devices/system/node")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, dirent := range dirents {
+		if submatches := nodeNRegex.FindStringSubmatch(dirent.Name()); submatches != nil {
+			if id, err := strconv.ParseUint(submatches[1], 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// getNumaNodes reads the NUMA topology from /sys/devices/system/node/nodeN,
+// and the node of each PCI device from /sys/bus/pci/devices
+func getNumaNodes() ([]NumaNode, error) {
+	ids, err := sysNodeIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := pciDevicesByNode()
+
+	nodes := make([]NumaNode, 0, len(ids))
+	for _, id := range ids {
+		dir := fmt.Sprintf("/sys/devices/system/node/node%d/", id)
+		node := NumaNode{
+			ID:         id,
+			CPUs:       []uint64{},
+			PCIDevices: []string{},
+			Distances:  []uint64{},
+		}
+
+		if cpuList, ok := readString(dir + "cpulist"); ok {
+			if cpus, ok := parseCpuList(cpuList); ok {
+				for cpu := range cpus {
+					node.CPUs = append(node.CPUs, cpu)
+				}
+				sort.Slice(node.CPUs, func(i, j int) bool { return node.CPUs[i] < node.CPUs[j] })
+			}
+		}
+
+		node.MemTotalBytes, node.MemFreeBytes = readNodeMemInfo(dir + "meminfo")
+
+		if distances, ok := readString(dir + "distance"); ok {
+			for _, field := range strings.Fields(distances) {
+				if distance, err := strconv.ParseUint(field, 10, 64); err == nil {
+					node.Distances = append(node.Distances, distance)
+				}
+			}
+		}
+
+		if nodeDevices, ok := devices[int64(id)]; ok {
+			node.PCIDevices = nodeDevices
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// readNodeMemInfo reads the total and free memory of a node from its meminfo
+// file, whose lines use the format `Node 0 MemTotal:        5603064 kB`
+func readNodeMemInfo(path string) (total, free uint64) {
+	file, err := os.Open(prefix + path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 4 && fields[4] == "kB" {
+			value *= 1024
+		}
+		switch fields[2] {
+		case "MemTotal:":
+			total = value
+		case "MemFree:":
+			free = value
+		}
+	}
+
+	return total, free
+}
+
+// pciDevicesByNode returns the sorted addresses of the PCI devices attached
+// to each NUMA node.  Devices which are not attached to a node (numa_node is
+// -1) are skipped.
+func pciDevicesByNode() map[int64][]string {
+	devices := map[int64][]string{}
+
+	dirents, err := os.ReadDir(prefix + "/sys/bus/pci/devices")
+	if err != nil {
+		return devices
+	}
+
+	for _, dirent := range dirents {
+		content, ok := readString(filepath.Join("/sys/bus/pci/devices", dirent.Name(), "numa_node"))
+		if !ok {
+			continue
+		}
+		node, err := strconv.ParseInt(content, 10, 64)
+		if err != nil || node < 0 {
+			continue
+		}
+		devices[node] = append(devices[node], dirent.Name())
+	}
+	for _, nodeDevices := range devices {
+		sort.Strings(nodeDevices)
+	}
+
+	return devices
+}
+
+// addSysNumaNodes sets the cpu_numa_nodes entry of cpuInfo to the number of
+// nodes in /sys/devices/system/node, which is 0 on kernels built without
+// NUMA support
+func addSysNumaNodes(cpuInfo map[string]string) {
+	ids, _ := sysNodeIDs()
+	cpuInfo["cpu_numa_nodes"] = strconv.Itoa(len(ids))
+}
@@ -0,0 +1,62 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetNumaNodes(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/devices/system/node/node0/cpulist": "0-3,8-11\n",
+		"sys/devices/system/node/node0/meminfo": `Node 0 MemTotal:       65536 kB
+Node 0 MemFree:        16384 kB
+Node 0 MemUsed:        49152 kB
+Node 0 HugePages_Total:     0
+`,
+		"sys/devices/system/node/node0/distance": "10 21\n",
+		"sys/devices/system/node/node1/cpulist":  "4-7,12-15\n",
+		"sys/devices/system/node/node1/meminfo": `Node 1 MemTotal:       32768 kB
+Node 1 MemFree:         8192 kB
+`,
+		"sys/devices/system/node/node1/distance":     "21 10\n",
+		"sys/devices/system/node/possible":           "0-1\n",
+		"sys/bus/pci/devices/0000:00:00.0/numa_node": "0\n",
+		"sys/bus/pci/devices/0000:3b:00.0/numa_node": "1\n",
+		"sys/bus/pci/devices/0000:17:00.0/numa_node": "0\n",
+		"sys/bus/pci/devices/0000:00:1f.0/numa_node": "-1\n",
+	})
+
+	nodes, err := getNumaNodes()
+	require.NoError(t, err)
+	require.Equal(t, []NumaNode{
+		{
+			ID:            0,
+			CPUs:          []uint64{0, 1, 2, 3, 8, 9, 10, 11},
+			MemTotalBytes: 65536 * 1024,
+			MemFreeBytes:  16384 * 1024,
+			PCIDevices:    []string{"0000:00:00.0", "0000:17:00.0"},
+			Distances:     []uint64{10, 21},
+		},
+		{
+			ID:            1,
+			CPUs:          []uint64{4, 5, 6, 7, 12, 13, 14, 15},
+			MemTotalBytes: 32768 * 1024,
+			MemFreeBytes:  8192 * 1024,
+			PCIDevices:    []string{"0000:3b:00.0"},
+			Distances:     []uint64{21, 10},
+		},
+	}, nodes)
+
+	cpuInfo := map[string]string{}
+	addSysNumaNodes(cpuInfo)
+	require.Equal(t, map[string]string{"cpu_numa_nodes": "2"}, cpuInfo)
+}
@@ -0,0 +1,101 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// Power holds the power limits, thermal zones and idle states of the CPUs,
+// as returned by GetPower.  Each list is empty when the matching sysfs tree
+// is absent (eg. in virtual machines).
+type Power struct {
+	// RAPL lists the RAPL zones of each package, from
+	// /sys/class/powercap/intel-rapl*
+	RAPL []RAPLZone `json:"rapl"`
+	// ThermalZones lists the thermal zones, from /sys/class/thermal
+	ThermalZones []ThermalZone `json:"thermal_zones"`
+	// IdleStates lists the idle states of each logical processor, from
+	// /sys/devices/system/cpu/cpuN/cpuidle
+	IdleStates []CPUIdleStates `json:"idle_states"`
+}
+
+// RAPLZone is a RAPL (Running Average Power Limit) power zone, such as a
+// package or its DRAM
+type RAPLZone struct {
+	// ID is the powercap zone (eg. "intel-rapl:0")
+	ID string `json:"id"`
+	// Name is the name of the zone (eg. "package-0", "dram", "core")
+	Name string `json:"name"`
+	// Enabled tells whether the power limits of the zone are enforced
+	Enabled bool `json:"enabled"`
+	// EnergyMicrojoules is the energy counter of the zone, which wraps at
+	// MaxEnergyRangeMicrojoules.  It is only readable by root on recent
+	// kernels, and is 0 otherwise.
+	EnergyMicrojoules uint64 `json:"energy_uj"`
+	// MaxEnergyRangeMicrojoules is the range of the energy counter
+	MaxEnergyRangeMicrojoules uint64 `json:"max_energy_range_uj"`
+	// Constraints lists the power limits of the zone
+	Constraints []RAPLConstraint `json:"constraints"`
+	// Subzones lists the zones within this zone (eg. "core" in a package)
+	Subzones []RAPLZone `json:"subzones"`
+}
+
+// RAPLConstraint is a power limit of a RAPL zone
+type RAPLConstraint struct {
+	// Name is the name of the constraint (eg. "long_term", "short_term")
+	Name string `json:"name"`
+	// PowerLimitMicrowatts is the power the zone is limited to
+	PowerLimitMicrowatts uint64 `json:"power_limit_uw"`
+	// TimeWindowMicroseconds is the window the power is averaged over
+	TimeWindowMicroseconds uint64 `json:"time_window_us"`
+	// MaxPowerMicrowatts is the maximum allowed power limit, 0 if unknown
+	MaxPowerMicrowatts uint64 `json:"max_power_uw"`
+}
+
+// ThermalZone is a thermal zone with its current temperature and trip
+// points
+type ThermalZone struct {
+	// Zone is the name of the zone in sysfs (eg. "thermal_zone0")
+	Zone string `json:"zone"`
+	// Type is the type of the zone (eg. "x86_pkg_temp", "acpitz")
+	Type string `json:"type"`
+	// TemperatureCelsius is the current temperature
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+	// TripPoints lists the temperatures at which the zone acts
+	TripPoints []ThermalTripPoint `json:"trip_points"`
+}
+
+// ThermalTripPoint is a temperature at which a thermal zone acts
+type ThermalTripPoint struct {
+	// Type is the type of the trip point: "active", "passive", "hot" or
+	// "critical"
+	Type string `json:"type"`
+	// TemperatureCelsius is the temperature of the trip point
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+}
+
+// CPUIdleStates lists the idle states of a logical processor
+type CPUIdleStates struct {
+	// CPU is the logical processor ID
+	CPU uint64 `json:"cpu"`
+	// States lists the idle states, from the shallowest to the deepest
+	States []CPUIdleState `json:"states"`
+}
+
+// CPUIdleState is an idle (C-) state of a logical processor
+type CPUIdleState struct {
+	// Name is the name of the state (eg. "POLL", "C1E", "C6")
+	Name string `json:"name"`
+	// Description is the description of the state
+	Description string `json:"description"`
+	// LatencyMicroseconds is the exit latency of the state
+	LatencyMicroseconds uint64 `json:"latency_us"`
+	// ResidencyMicroseconds is the target residency of the state
+	ResidencyMicroseconds uint64 `json:"residency_us"`
+	// Usage is the number of times the state was entered
+	Usage uint64 `json:"usage"`
+	// TimeMicroseconds is the total time spent in the state
+	TimeMicroseconds uint64 `json:"time_us"`
+	// Disabled tells whether the state was disabled
+	Disabled bool `json:"disabled"`
+}
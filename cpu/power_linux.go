@@ -0,0 +1,184 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// raplZoneRegex recognizes the top-level RAPL zones in /sys/class/powercap,
+// eg. `intel-rapl:0` or `intel-rapl-mmio:0`
+var raplZoneRegex = regexp.MustCompile(`^intel-rapl(?:-mmio)?:([0-9]+)$`)
+
+// thermalZoneRegex recognizes directories named `thermal_zoneNN`
+var thermalZoneRegex = regexp.MustCompile("^thermal_zone([0-9]+)$")
+
+// GetPower returns the RAPL power limits, thermal zones and idle states of
+// the CPUs.  Missing sysfs trees result in empty lists rather than errors, as
+// they are commonly absent in virtual machines and containers.
+func GetPower() (*Power, error) {
+	power := &Power{
+		RAPL:         getRAPLZones(),
+		ThermalZones: getThermalZones(),
+		IdleStates:   []CPUIdleStates{},
+	}
+
+	procIDs, err := sysCpuIDs()
+	if err != nil {
+		return power, nil
+	}
+	for _, procID := range procIDs {
+		if states := getIdleStates(procID); len(states) != 0 {
+			power.IdleStates = append(power.IdleStates, CPUIdleStates{CPU: procID, States: states})
+		}
+	}
+
+	return power, nil
+}
+
+// listNumbered returns the entries of dir matching re, sorted by the number
+// captured by re and then by name
+func listNumbered(dir string, re *regexp.Regexp) []string {
+	dirents, err := os.ReadDir(prefix + dir)
+	if err != nil {
+		return nil
+	}
+
+	type entry struct {
+		name   string
+		number uint64
+	}
+	var entries []entry
+	for _, dirent := range dirents {
+		if submatches := re.FindStringSubmatch(dirent.Name()); submatches != nil {
+			if number, err := strconv.ParseUint(submatches[1], 10, 64); err == nil {
+				entries = append(entries, entry{dirent.Name(), number})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].number != entries[j].number {
+			return entries[i].number < entries[j].number
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// getRAPLZones returns the top-level RAPL zones (one per package) along with
+// their subzones
+func getRAPLZones() []RAPLZone {
+	zones := []RAPLZone{}
+	for _, id := range listNumbered("/sys/class/powercap", raplZoneRegex) {
+		zones = append(zones, getRAPLZone(id))
+	}
+	return zones
+}
+
+// getRAPLZone reads the RAPL zone with the given ID, and its subzones (named
+// after the ID of their parent, eg. `intel-rapl:0:1`)
+func getRAPLZone(id string) RAPLZone {
+	dir := "/sys/class/powercap/" + id + "/"
+	zone := RAPLZone{
+		ID:          id,
+		Constraints: []RAPLConstraint{},
+		Subzones:    []RAPLZone{},
+	}
+
+	zone.Name, _ = readString(dir + "name")
+	if enabled, ok := readUint(dir + "enabled"); ok {
+		zone.Enabled = enabled != 0
+	}
+	zone.EnergyMicrojoules, _ = readUint(dir + "energy_uj")
+	zone.MaxEnergyRangeMicrojoules, _ = readUint(dir + "max_energy_range_uj")
+
+	for i := 0; ; i++ {
+		constraint := fmt.Sprintf("%sconstraint_%d_", dir, i)
+		name, ok := readString(constraint + "name")
+		if !ok {
+			break
+		}
+		c := RAPLConstraint{Name: name}
+		c.PowerLimitMicrowatts, _ = readUint(constraint + "power_limit_uw")
+		c.TimeWindowMicroseconds, _ = readUint(constraint + "time_window_us")
+		c.MaxPowerMicrowatts, _ = readUint(constraint + "max_power_uw")
+		zone.Constraints = append(zone.Constraints, c)
+	}
+
+	subzoneRegex := regexp.MustCompile("^" + regexp.QuoteMeta(id) + ":([0-9]+)$")
+	for _, subzoneID := range listNumbered("/sys/class/powercap", subzoneRegex) {
+		zone.Subzones = append(zone.Subzones, getRAPLZone(subzoneID))
+	}
+
+	return zone
+}
+
+// getThermalZones returns the thermal zones and their trip points.
+// Temperatures are reported by sysfs in millidegrees Celsius.
+func getThermalZones() []ThermalZone {
+	zones := []ThermalZone{}
+	for _, name := range listNumbered("/sys/class/thermal", thermalZoneRegex) {
+		dir := "/sys/class/thermal/" + name + "/"
+		zone := ThermalZone{Zone: name, TripPoints: []ThermalTripPoint{}}
+
+		zone.Type, _ = readString(dir + "type")
+		// reading the temperature fails on some zones whose sensor is not
+		// ready, leaving it at 0
+		if temp, ok := readInt(dir + "temp"); ok {
+			zone.TemperatureCelsius = float64(temp) / 1000
+		}
+
+		for i := 0; ; i++ {
+			trip := fmt.Sprintf("%strip_point_%d_", dir, i)
+			tripType, ok := readString(trip + "type")
+			if !ok {
+				break
+			}
+			tripPoint := ThermalTripPoint{Type: tripType}
+			if temp, ok := readInt(trip + "temp"); ok {
+				tripPoint.TemperatureCelsius = float64(temp) / 1000
+			}
+			zone.TripPoints = append(zone.TripPoints, tripPoint)
+		}
+
+		zones = append(zones, zone)
+	}
+	return zones
+}
+
+// getIdleStates returns the idle states of the given logical processor from
+// /sys/devices/system/cpu/cpuN/cpuidle/stateM
+func getIdleStates(procID uint64) []CPUIdleState {
+	var states []CPUIdleState
+	for i := 0; ; i++ {
+		dir := fmt.Sprintf("cpu%d/cpuidle/state%d/", procID, i)
+		name, ok := sysCpuString(dir + "name")
+		if !ok {
+			break
+		}
+
+		state := CPUIdleState{Name: name}
+		state.Description, _ = sysCpuString(dir + "desc")
+		state.LatencyMicroseconds, _ = sysCpuInt(dir + "latency")
+		state.ResidencyMicroseconds, _ = sysCpuInt(dir + "residency")
+		state.Usage, _ = sysCpuInt(dir + "usage")
+		state.TimeMicroseconds, _ = sysCpuInt(dir + "time")
+		if disabled, ok := sysCpuInt(dir + "disable"); ok {
+			state.Disabled = disabled != 0
+		}
+		states = append(states, state)
+	}
+	return states
+}
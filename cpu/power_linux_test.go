@@ -0,0 +1,128 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetPower(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/class/powercap/intel-rapl/enabled":                         "1\n",
+		"sys/class/powercap/intel-rapl:0/name":                          "package-0\n",
+		"sys/class/powercap/intel-rapl:0/enabled":                       "1\n",
+		"sys/class/powercap/intel-rapl:0/energy_uj":                     "81270442917\n",
+		"sys/class/powercap/intel-rapl:0/max_energy_range_uj":           "262143328850\n",
+		"sys/class/powercap/intel-rapl:0/constraint_0_name":             "long_term\n",
+		"sys/class/powercap/intel-rapl:0/constraint_0_power_limit_uw":   "150000000\n",
+		"sys/class/powercap/intel-rapl:0/constraint_0_time_window_us":   "999424\n",
+		"sys/class/powercap/intel-rapl:0/constraint_0_max_power_uw":     "150000000\n",
+		"sys/class/powercap/intel-rapl:0/constraint_1_name":             "short_term\n",
+		"sys/class/powercap/intel-rapl:0/constraint_1_power_limit_uw":   "180000000\n",
+		"sys/class/powercap/intel-rapl:0/constraint_1_time_window_us":   "7808\n",
+		"sys/class/powercap/intel-rapl:0:0/name":                        "dram\n",
+		"sys/class/powercap/intel-rapl:0:0/enabled":                     "0\n",
+		"sys/class/powercap/intel-rapl:0:0/constraint_0_name":           "long_term\n",
+		"sys/class/powercap/intel-rapl:0:0/constraint_0_power_limit_uw": "0\n",
+		"sys/class/powercap/intel-rapl:0:0/constraint_0_time_window_us": "976\n",
+		// energy_uj is only readable by root, which is simulated by its absence
+		"sys/class/powercap/intel-rapl:1/name":                 "package-1\n",
+		"sys/class/powercap/intel-rapl:1/enabled":              "1\n",
+		"sys/class/powercap/intel-rapl:1/max_energy_range_uj":  "262143328850\n",
+		"sys/class/thermal/thermal_zone0/type":                 "acpitz\n",
+		"sys/class/thermal/thermal_zone0/temp":                 "27800\n",
+		"sys/class/thermal/thermal_zone0/trip_point_0_type":    "critical\n",
+		"sys/class/thermal/thermal_zone0/trip_point_0_temp":    "105000\n",
+		"sys/class/thermal/thermal_zone10/type":                "x86_pkg_temp\n",
+		"sys/class/thermal/thermal_zone10/temp":                "-5000\n",
+		"sys/class/thermal/thermal_zone2/type":                 "iwlwifi_1\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/name":      "POLL\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/desc":      "CPUIDLE CORE POLL IDLE\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/latency":   "0\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/residency": "0\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/usage":     "1234\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/time":      "5678\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state0/disable":   "0\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/name":      "C6\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/desc":      "MWAIT 0x20\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/latency":   "170\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/residency": "600\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/usage":     "42\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/time":      "987654\n",
+		"sys/devices/system/cpu/cpu0/cpuidle/state1/disable":   "1\n",
+		"sys/devices/system/cpu/cpu1/online":                   "0\n",
+	})
+
+	power, err := GetPower()
+	require.NoError(t, err)
+	require.Equal(t, &Power{
+		RAPL: []RAPLZone{
+			{
+				ID:                        "intel-rapl:0",
+				Name:                      "package-0",
+				Enabled:                   true,
+				EnergyMicrojoules:         81270442917,
+				MaxEnergyRangeMicrojoules: 262143328850,
+				Constraints: []RAPLConstraint{
+					{Name: "long_term", PowerLimitMicrowatts: 150000000, TimeWindowMicroseconds: 999424, MaxPowerMicrowatts: 150000000},
+					{Name: "short_term", PowerLimitMicrowatts: 180000000, TimeWindowMicroseconds: 7808},
+				},
+				Subzones: []RAPLZone{
+					{
+						ID:          "intel-rapl:0:0",
+						Name:        "dram",
+						Constraints: []RAPLConstraint{{Name: "long_term", TimeWindowMicroseconds: 976}},
+						Subzones:    []RAPLZone{},
+					},
+				},
+			},
+			{
+				ID:                        "intel-rapl:1",
+				Name:                      "package-1",
+				Enabled:                   true,
+				MaxEnergyRangeMicrojoules: 262143328850,
+				Constraints:               []RAPLConstraint{},
+				Subzones:                  []RAPLZone{},
+			},
+		},
+		ThermalZones: []ThermalZone{
+			{
+				Zone:               "thermal_zone0",
+				Type:               "acpitz",
+				TemperatureCelsius: 27.8,
+				TripPoints:         []ThermalTripPoint{{Type: "critical", TemperatureCelsius: 105}},
+			},
+			{Zone: "thermal_zone2", Type: "iwlwifi_1", TripPoints: []ThermalTripPoint{}},
+			{Zone: "thermal_zone10", Type: "x86_pkg_temp", TemperatureCelsius: -5, TripPoints: []ThermalTripPoint{}},
+		},
+		IdleStates: []CPUIdleStates{
+			{
+				CPU: 0,
+				States: []CPUIdleState{
+					{Name: "POLL", Description: "CPUIDLE CORE POLL IDLE", Usage: 1234, TimeMicroseconds: 5678},
+					{Name: "C6", Description: "MWAIT 0x20", LatencyMicroseconds: 170, ResidencyMicroseconds: 600, Usage: 42, TimeMicroseconds: 987654, Disabled: true},
+				},
+			},
+		},
+	}, power)
+}
+
+func TestGetPowerMissing(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	power, err := GetPower()
+	require.NoError(t, err)
+	require.Equal(t, &Power{
+		RAPL:         []RAPLZone{},
+		ThermalZones: []ThermalZone{},
+		IdleStates:   []CPUIdleStates{},
+	}, power)
+}
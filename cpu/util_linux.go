@@ -7,9 +7,11 @@ package cpu
 
 import (
 	"bufio"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -17,14 +19,74 @@ import (
 var prefix = "" // only used for testing
 var listRangeRegex = regexp.MustCompile("([0-9]+)-([0-9]+)$")
 
+// cpuNRegex recognizes directories named `cpuNN`
+var cpuNRegex = regexp.MustCompile("^cpu([0-9]+)$")
+
+// sysCpuIDs returns the sorted IDs of the logical processors present in
+// /sys/devices/system/cpu
+func sysCpuIDs() ([]uint64, error) {
+	dirents, err := os.ReadDir(prefix + "/sys/devices/system/cpu")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, dirent := range dirents {
+		if submatches := cpuNRegex.FindStringSubmatch(dirent.Name()); submatches != nil {
+			if id, err := strconv.ParseUint(submatches[1], 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// sysCpuString reads a whitespace-trimmed string from a file in /sys/devices/system/cpu
+func sysCpuString(path string) (string, bool) {
+	return readString("/sys/devices/system/cpu/" + path)
+}
+
+// readString reads a whitespace-trimmed string from the file at the given
+// absolute path
+func readString(path string) (string, bool) {
+	content, err := ioutil.ReadFile(prefix + path)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(content)), true
+}
+
 // sysCpuInt reads an integer from a file in /sys/devices/system/cpu
 func sysCpuInt(path string) (uint64, bool) {
-	content, err := ioutil.ReadFile(prefix + "/sys/devices/system/cpu/" + path)
+	return readUint("/sys/devices/system/cpu/" + path)
+}
+
+// readUint reads an unsigned integer from the file at the given absolute path
+func readUint(path string) (uint64, bool) {
+	content, ok := readString(path)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(content, 0, 64)
 	if err != nil {
 		return 0, false
 	}
 
-	value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 0, 64)
+	return value, true
+}
+
+// readInt reads a signed integer from the file at the given absolute path
+func readInt(path string) (int64, bool) {
+	content, ok := readString(path)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.ParseInt(content, 0, 64)
 	if err != nil {
 		return 0, false
 	}
@@ -71,8 +133,14 @@ func sysCpuList(path string) (map[uint64]struct{}, bool) {
 		return nil, false
 	}
 
+	return parseCpuList(string(content))
+}
+
+// parseCpuList parses a list of integers in the format described for
+// sysCpuList
+func parseCpuList(content string) (map[uint64]struct{}, bool) {
 	result := map[uint64]struct{}{}
-	contentStr := strings.TrimSpace(string(content))
+	contentStr := strings.TrimSpace(content)
 	if len(contentStr) == 0 {
 		return result, true
 	}
@@ -104,10 +172,11 @@ func sysCpuList(path string) (map[uint64]struct{}, bool) {
 	return result, true
 }
 
-// readProcCpuInfo reads /proc/cpuinfo.  The file is structured as a set of
-// blank-line-separated stanzas, and each stanza is a map of string to string,
-// with whitespace stripped.
-func readProcCpuInfo() ([]map[string]string, error) {
+// readProcCpuInfoStanzas reads /proc/cpuinfo.  The file is structured as a
+// set of blank-line-separated stanzas, and each stanza is a map of string to
+// string, with whitespace stripped.  All stanzas are returned, including
+// those which do not describe a processor.
+func readProcCpuInfoStanzas() ([]map[string]string, error) {
 	file, err := os.Open(prefix + "/proc/cpuinfo")
 	if err != nil {
 		return nil, err
@@ -142,6 +211,17 @@ func readProcCpuInfo() ([]map[string]string, error) {
 		return nil, err
 	}
 
+	return stanzas, nil
+}
+
+// readProcCpuInfo reads /proc/cpuinfo like readProcCpuInfoStanzas, only
+// returning the stanzas describing a processor.
+func readProcCpuInfo() ([]map[string]string, error) {
+	stanzas, err := readProcCpuInfoStanzas()
+	if err != nil {
+		return nil, err
+	}
+
 	// On some platforms, such as rPi, there are stanzas in this file that do
 	// not correspond to processors.  It doesn't seem this file is intended for
 	// machine consumption!  So, we filter those out.
@@ -155,3 +235,71 @@ func readProcCpuInfo() ([]map[string]string, error) {
 
 	return results, nil
 }
+
+// procIDs returns the IDs of the processors described by the given
+// /proc/cpuinfo stanzas
+func procIDs(procCpu []map[string]string) []uint64 {
+	ids := make([]uint64, 0, len(procCpu))
+	for _, stanza := range procCpu {
+		if procID, err := strconv.ParseUint(stanza["processor"], 0, 64); err == nil {
+			ids = append(ids, procID)
+		}
+	}
+	return ids
+}
+
+// sysCpuTopology counts the distinct core and package IDs of the given
+// logical processors, based on /sys/devices/system/cpu/cpuN/topology
+func sysCpuTopology(procIDs []uint64) (cores int, packages int) {
+	coreSet := map[uint64]struct{}{}
+	packageSet := map[uint64]struct{}{}
+	for _, procID := range procIDs {
+		if coreID, ok := sysCpuInt(fmt.Sprintf("cpu%d/topology/core_id", procID)); ok {
+			coreSet[coreID] = struct{}{}
+		}
+
+		if pkgID, ok := sysCpuInt(fmt.Sprintf("cpu%d/topology/physical_package_id", procID)); ok {
+			packageSet[pkgID] = struct{}{}
+		}
+	}
+	return len(coreSet), len(packageSet)
+}
+
+// sysCpuPhysicalCores counts the distinct (package, core) ID pairs of the
+// given logical processors, core IDs restarting at 0 on each package on x86
+func sysCpuPhysicalCores(procIDs []uint64) int {
+	coreSet := map[[2]uint64]struct{}{}
+	for _, procID := range procIDs {
+		coreID, ok := sysCpuInt(fmt.Sprintf("cpu%d/topology/core_id", procID))
+		if !ok {
+			continue
+		}
+		pkgID, _ := sysCpuInt(fmt.Sprintf("cpu%d/topology/physical_package_id", procID))
+		coreSet[[2]uint64{pkgID, coreID}] = struct{}{}
+	}
+	return len(coreSet)
+}
+
+// addSysCacheSizes sets the cache_size_lN entries of cpuInfo from the caches
+// in /sys/devices/system/cpu, as well as cache_size if it is not already set.
+// Nothing is set when sysfs reports no cache.
+func addSysCacheSizes(cpuInfo map[string]string) {
+	caches, err := getCaches()
+	if err != nil || len(caches) == 0 {
+		return
+	}
+	setCacheSizes(cpuInfo, cacheSizesByLevel(caches))
+}
+
+// setCacheSizes sets the cache_size_lN entries of cpuInfo from the given
+// sizes by level, as well as cache_size if it is not already set
+func setCacheSizes(cpuInfo map[string]string, cacheSizes map[uint64]uint64) {
+	cpuInfo["cache_size_l1"] = strconv.FormatUint(cacheSizes[1], 10)
+	cpuInfo["cache_size_l2"] = strconv.FormatUint(cacheSizes[2], 10)
+	cpuInfo["cache_size_l3"] = strconv.FormatUint(cacheSizes[3], 10)
+
+	if _, ok := cpuInfo["cache_size"]; !ok {
+		// cache_size uses the format '9216 KB'
+		cpuInfo["cache_size"] = fmt.Sprintf("%d KB", (cacheSizes[1]+cacheSizes[2]+cacheSizes[3])/1024)
+	}
+}
@@ -12,11 +12,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
 )
 
 func TestSysCpuInt(t *testing.T) {
-	prefix = t.TempDir()
-	defer func() { prefix = "" }()
+	utils.SetTestPrefix(t, &prefix)
 	os.MkdirAll(filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu")), 0o777)
 	path := filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu/somefile"))
 
@@ -54,8 +55,7 @@ func TestSysCpuInt(t *testing.T) {
 }
 
 func TestSysCpuSize(t *testing.T) {
-	prefix = t.TempDir()
-	defer func() { prefix = "" }()
+	utils.SetTestPrefix(t, &prefix)
 	os.MkdirAll(filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu")), 0o777)
 	path := filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu/somefile"))
 
@@ -107,8 +107,7 @@ func TestSysCpuSize(t *testing.T) {
 }
 
 func TestSysCpuList(t *testing.T) {
-	prefix = t.TempDir()
-	defer func() { prefix = "" }()
+	utils.SetTestPrefix(t, &prefix)
 	os.MkdirAll(filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu")), 0o777)
 	path := filepath.Join(prefix, filepath.FromSlash("sys/devices/system/cpu/somefile"))
 
@@ -165,8 +164,7 @@ func TestSysCpuList(t *testing.T) {
 }
 
 func TestReadProcCpuInfo(t *testing.T) {
-	prefix = t.TempDir()
-	defer func() { prefix = "" }()
+	utils.SetTestPrefix(t, &prefix)
 	os.MkdirAll(filepath.Join(prefix, filepath.FromSlash("proc")), 0o777)
 	path := filepath.Join(prefix, filepath.FromSlash("proc/cpuinfo"))
 
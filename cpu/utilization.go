@@ -0,0 +1,45 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// Utilization holds the CPU utilization sampled over a window of time, as
+// returned by SampleUtilization
+type Utilization struct {
+	// WindowSeconds is the duration of the sample in seconds
+	WindowSeconds float64 `json:"window_seconds"`
+	// Total is the utilization aggregated over all logical processors
+	Total CoreUtilization `json:"total"`
+	// Cores is the utilization of each logical processor
+	Cores []CoreUtilization `json:"cores"`
+
+	// LoadAverage1 is the 1-minute load average at the end of the sample
+	LoadAverage1 float64 `json:"load_average_1"`
+	// LoadAverage5 is the 5-minute load average at the end of the sample
+	LoadAverage5 float64 `json:"load_average_5"`
+	// LoadAverage15 is the 15-minute load average at the end of the sample
+	LoadAverage15 float64 `json:"load_average_15"`
+	// RunQueue is the number of currently runnable tasks
+	RunQueue uint64 `json:"run_queue"`
+	// Tasks is the total number of tasks
+	Tasks uint64 `json:"tasks"`
+}
+
+// CoreUtilization holds the share of time (in percent) a processor spent in
+// each state during a sample
+type CoreUtilization struct {
+	// CPU is the name of the processor as in /proc/stat ("cpu" for the total,
+	// "cpuN" otherwise)
+	CPU     string  `json:"cpu"`
+	User    float64 `json:"user"`
+	Nice    float64 `json:"nice"`
+	System  float64 `json:"system"`
+	Idle    float64 `json:"idle"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	SoftIRQ float64 `json:"softirq"`
+	// Steal is the time stolen by the hypervisor to run other guests
+	Steal float64 `json:"steal"`
+}
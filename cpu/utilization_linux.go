@@ -0,0 +1,197 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cpuTimes holds the cumulative time (in USER_HZ) spent by a processor in
+// each state, as read from a `cpu` line of /proc/stat
+type cpuTimes struct {
+	name                                                  string
+	user, nice, system, idle, iowait, irq, softirq, steal uint64
+}
+
+// total returns the sum of all states.  Guest time is not included as it is
+// already accounted for in user and nice.
+func (t cpuTimes) total() uint64 {
+	return t.user + t.nice + t.system + t.idle + t.iowait + t.irq + t.softirq + t.steal
+}
+
+// SampleUtilization reads /proc/stat twice, window apart, and returns the
+// utilization of each processor over that window along with the load average
+// and run queue from /proc/loadavg.
+func SampleUtilization(window time.Duration) (*Utilization, error) {
+	before, err := readProcStat()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	time.Sleep(window)
+
+	after, err := readProcStat()
+	if err != nil {
+		return nil, err
+	}
+
+	u := computeUtilization(before, after)
+	u.WindowSeconds = time.Since(start).Seconds()
+
+	if err := readLoadAvg(u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// readProcStat reads the `cpu` and `cpuN` lines of /proc/stat
+func readProcStat() ([]cpuTimes, error) {
+	file, err := os.Open(prefix + "/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var result []cpuTimes
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		// older kernels report fewer columns, which are then left at 0
+		var values [8]uint64
+		for i := 0; i < len(values) && i+1 < len(fields); i++ {
+			values[i], err = strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse /proc/stat line %q: %s", scanner.Text(), err)
+			}
+		}
+
+		result = append(result, cpuTimes{
+			name:    fields[0],
+			user:    values[0],
+			nice:    values[1],
+			system:  values[2],
+			idle:    values[3],
+			iowait:  values[4],
+			irq:     values[5],
+			softirq: values[6],
+			steal:   values[7],
+		})
+	}
+
+	if scanner.Err() != nil {
+		return nil, scanner.Err()
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no cpu line found in /proc/stat")
+	}
+
+	return result, nil
+}
+
+// computeUtilization returns the utilization between two reads of /proc/stat.
+// Processors which are not present in both reads (eg. because they were
+// hot-plugged) are skipped.
+func computeUtilization(before, after []cpuTimes) *Utilization {
+	u := &Utilization{Cores: []CoreUtilization{}}
+
+	previous := make(map[string]cpuTimes, len(before))
+	for _, t := range before {
+		previous[t.name] = t
+	}
+
+	for _, t := range after {
+		prev, ok := previous[t.name]
+		if !ok {
+			continue
+		}
+		core := coreUtilization(prev, t)
+		if t.name == "cpu" {
+			u.Total = core
+		} else {
+			u.Cores = append(u.Cores, core)
+		}
+	}
+
+	return u
+}
+
+// coreUtilization returns the share of time spent in each state between two
+// reads of the times of a processor
+func coreUtilization(before, after cpuTimes) CoreUtilization {
+	core := CoreUtilization{CPU: after.name}
+
+	total := after.total() - before.total()
+	if after.total() < before.total() || total == 0 {
+		return core
+	}
+
+	pct := func(b, a uint64) float64 {
+		if a < b {
+			return 0
+		}
+		return float64(a-b) * 100 / float64(total)
+	}
+	core.User = pct(before.user, after.user)
+	core.Nice = pct(before.nice, after.nice)
+	core.System = pct(before.system, after.system)
+	core.Idle = pct(before.idle, after.idle)
+	core.IOWait = pct(before.iowait, after.iowait)
+	core.IRQ = pct(before.irq, after.irq)
+	core.SoftIRQ = pct(before.softirq, after.softirq)
+	core.Steal = pct(before.steal, after.steal)
+
+	return core
+}
+
+// readLoadAvg fills the load average and run queue of u from /proc/loadavg,
+// which uses the format `0.20 0.18 0.12 1/80 11206`
+func readLoadAvg(u *Utilization) error {
+	content, ok := readString("/proc/loadavg")
+	if !ok {
+		return errors.New("could not read /proc/loadavg")
+	}
+
+	fields := strings.Fields(content)
+	if len(fields) < 4 {
+		return fmt.Errorf("unexpected /proc/loadavg content %q", content)
+	}
+
+	var err error
+	if u.LoadAverage1, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return err
+	}
+	if u.LoadAverage5, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return err
+	}
+	if u.LoadAverage15, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return err
+	}
+
+	tasks := strings.SplitN(fields[3], "/", 2)
+	if len(tasks) != 2 {
+		return fmt.Errorf("unexpected /proc/loadavg content %q", content)
+	}
+	if u.RunQueue, err = strconv.ParseUint(tasks[0], 10, 64); err != nil {
+		return err
+	}
+	if u.Tasks, err = strconv.ParseUint(tasks[1], 10, 64); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestReadProcStat(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/stat": `cpu  200 10 100 1000 50 5 5 30 0 0
+cpu0 100 5 50 500 25 3 2 15 0 0
+cpu1 100 5 50 500 25 2 3 15 0 0
+intr 12345 0 0
+ctxt 987654
+procs_running 2
+`,
+	})
+
+	times, err := readProcStat()
+	require.NoError(t, err)
+	require.Equal(t, []cpuTimes{
+		{name: "cpu", user: 200, nice: 10, system: 100, idle: 1000, iowait: 50, irq: 5, softirq: 5, steal: 30},
+		{name: "cpu0", user: 100, nice: 5, system: 50, idle: 500, iowait: 25, irq: 3, softirq: 2, steal: 15},
+		{name: "cpu1", user: 100, nice: 5, system: 50, idle: 500, iowait: 25, irq: 2, softirq: 3, steal: 15},
+	}, times)
+}
+
+func TestComputeUtilization(t *testing.T) {
+	before := []cpuTimes{
+		{name: "cpu", user: 100, system: 100, idle: 1000},
+		{name: "cpu0", user: 50, system: 50, idle: 500},
+		{name: "cpu1", user: 50, system: 50, idle: 500},
+	}
+	after := []cpuTimes{
+		// 200 ticks elapsed over two processors
+		{name: "cpu", user: 150, system: 120, idle: 1080, iowait: 20, steal: 30},
+		{name: "cpu0", user: 100, system: 60, idle: 520, iowait: 10, steal: 10},
+		{name: "cpu1", user: 50, system: 60, idle: 560, iowait: 10, steal: 20},
+		// hot-plugged during the sample
+		{name: "cpu2", user: 10, idle: 10},
+	}
+
+	require.Equal(t, &Utilization{
+		Total: CoreUtilization{CPU: "cpu", User: 25, System: 10, Idle: 40, IOWait: 10, Steal: 15},
+		Cores: []CoreUtilization{
+			{CPU: "cpu0", User: 50, System: 10, Idle: 20, IOWait: 10, Steal: 10},
+			{CPU: "cpu1", User: 0, System: 10, Idle: 60, IOWait: 10, Steal: 20},
+		},
+	}, computeUtilization(before, after))
+}
+
+func TestSampleUtilization(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/stat":    "cpu  100 0 100 800 0 0 0 0 0 0\ncpu0 100 0 100 800 0 0 0 0 0 0\n",
+		"proc/loadavg": "0.20 0.18 0.12 3/812 11206\n",
+	})
+
+	u, err := SampleUtilization(time.Millisecond)
+	require.NoError(t, err)
+	require.Greater(t, u.WindowSeconds, 0.0)
+	require.Equal(t, 0.20, u.LoadAverage1)
+	require.Equal(t, 0.18, u.LoadAverage5)
+	require.Equal(t, 0.12, u.LoadAverage15)
+	require.Equal(t, uint64(3), u.RunQueue)
+	require.Equal(t, uint64(812), u.Tasks)
+	require.Len(t, u.Cores, 1)
+}
@@ -0,0 +1,79 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+// Virtualization describes whether the host runs on bare metal, in a virtual
+// machine or in a container
+type Virtualization struct {
+	// Type is one of "none" (bare metal), "full", "para" or "container"
+	Type string `json:"type"`
+	// HypervisorVendor is the detected hypervisor (eg. "kvm", "vmware",
+	// "microsoft", "xen"), empty on bare metal or when it could not be
+	// identified
+	HypervisorVendor string `json:"hypervisor_vendor,omitempty"`
+	// ContainerRuntime is the detected container runtime (eg. "docker",
+	// "podman", "lxc", "kubernetes"), empty outside of containers
+	ContainerRuntime string `json:"container_runtime,omitempty"`
+	// Confidence is one of "high", "medium" or "low", depending on how many
+	// sources support the result
+	Confidence string `json:"confidence"`
+	// Sources lists the evidence the result is based on, as `source:value`
+	Sources []string `json:"sources"`
+}
+
+// Values for Virtualization.Type
+const (
+	VirtualizationNone      = "none"
+	VirtualizationFull      = "full"
+	VirtualizationPara      = "para"
+	VirtualizationContainer = "container"
+)
+
+// cpuidHypervisorLeaf is the CPUID leaf returning the hypervisor vendor
+// signature in EBX, ECX and EDX
+const cpuidHypervisorLeaf = 0x40000000
+
+// hypervisorSignatures maps the CPUID hypervisor vendor signatures to a
+// vendor name
+var hypervisorSignatures = map[string]string{
+	"KVMKVMKVM\x00\x00\x00": "kvm",
+	"Linux KVM Hv":          "kvm",
+	"Microsoft Hv":          "microsoft",
+	"VMwareVMware":          "vmware",
+	"XenVMMXenVMM":          "xen",
+	"TCGTCGTCGTCG":          "qemu",
+	" lrpepyh  vr":          "parallels",
+	"bhyve bhyve ":          "bhyve",
+	"VBoxVBoxVBox":          "virtualbox",
+	"ACRNACRNACRN":          "acrn",
+	"QNXQVMBSQG\x00\x00":    "qnx",
+}
+
+// cpuidHypervisorVendor returns the hypervisor vendor signature reported by
+// CPUID, or false if CPUID is not available or does not report a hypervisor
+func cpuidHypervisorVendor() (string, bool) {
+	if cpuidFunc == nil {
+		return "", false
+	}
+
+	// the "hypervisor present" bit is bit 31 of ECX in leaf 1
+	if _, _, ecx, _ := cpuidFunc(1, 0); ecx&(1<<31) == 0 {
+		return "", false
+	}
+
+	_, ebx, ecx, edx := cpuidFunc(cpuidHypervisorLeaf, 0)
+	return string(registersToBytes(ebx, ecx, edx)), true
+}
+
+// registersToBytes returns the little-endian bytes of the given registers,
+// which is how CPUID encodes strings
+func registersToBytes(regs ...uint32) []byte {
+	b := make([]byte, 0, 4*len(regs))
+	for _, reg := range regs {
+		b = append(b, byte(reg), byte(reg>>8), byte(reg>>16), byte(reg>>24))
+	}
+	return b
+}
@@ -0,0 +1,250 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// dmiVendors maps substrings of the DMI product name, system vendor or BIOS
+// vendor to a hypervisor vendor name.  The cloud markers are also reported by
+// the bare-metal instances of these clouds (eg. EC2 *.metal), and are only
+// trusted along with another hint, see getVirtualization.
+var dmiVendors = []struct {
+	marker string
+	vendor string
+	cloud  bool
+}{
+	{"VMware", "vmware", false},
+	{"VirtualBox", "virtualbox", false},
+	{"innotek GmbH", "virtualbox", false},
+	{"KVM", "kvm", false},
+	{"QEMU", "qemu", false},
+	{"Google Compute Engine", "kvm", true},
+	{"Amazon EC2", "kvm", true},
+	{"HVM domU", "xen", false},
+	{"Xen", "xen", false},
+	{"Virtual Machine", "microsoft", false},
+	{"Parallels", "parallels", false},
+	{"BHYVE", "bhyve", false},
+}
+
+// dmiFiles are the files of /sys/class/dmi/id searched for dmiVendors
+var dmiFiles = []string{"product_name", "sys_vendor", "bios_vendor"}
+
+// cgroupContainerMarkers maps substrings of a cgroup path to the container
+// runtime which created it
+var cgroupContainerMarkers = []struct {
+	marker  string
+	runtime string
+}{
+	{"/kubepods", "kubernetes"},
+	{"/libpod", "podman"},
+	{"/docker", "docker"},
+	{"/crio-", "cri-o"},
+	{"/containerd", "containerd"},
+	{"/lxc", "lxc"},
+	{"/ecs/", "ecs"},
+}
+
+// getVirtualization combines several sources to tell whether this host is
+// bare metal, a virtual machine or a container:
+//   - the `hypervisor` flag in /proc/cpuinfo
+//   - the hypervisor vendor signature in CPUID leaf 0x40000000 (amd64 only)
+//   - /sys/hypervisor/type and /sys/hypervisor/guest_type (Xen)
+//   - the DMI product name and vendors in /sys/class/dmi/id
+//   - /.dockerenv, /run/.containerenv, the `container` variable in the
+//     environment of PID 1 and the cgroup of PID 1
+func getVirtualization() *Virtualization {
+	v := &Virtualization{Sources: []string{}}
+
+	// votes counts, for each hypervisor vendor, the number of sources
+	// identifying it
+	votes := map[string]int{}
+	cpuidVendor := ""
+	hypervisor := false
+
+	if procCpu, err := readProcCpuInfo(); err == nil && len(procCpu) != 0 {
+		for _, flag := range strings.Fields(procCpu[0]["flags"]) {
+			if flag == "hypervisor" {
+				hypervisor = true
+				v.Sources = append(v.Sources, "cpuinfo:hypervisor")
+				break
+			}
+		}
+	}
+
+	if signature, ok := cpuidHypervisorVendor(); ok {
+		hypervisor = true
+		v.Sources = append(v.Sources, "cpuid:"+strings.TrimRight(signature, "\x00"))
+		if vendor, ok := hypervisorSignatures[signature]; ok {
+			cpuidVendor = vendor
+			votes[vendor]++
+		}
+	}
+
+	guestType := ""
+	if hvType, ok := readString("/sys/hypervisor/type"); ok && hvType != "" {
+		hypervisor = true
+		v.Sources = append(v.Sources, "sys_hypervisor:"+hvType)
+		votes[hvType]++
+		guestType, _ = readString("/sys/hypervisor/guest_type")
+	}
+
+	dmi := map[string]string{}
+	for _, file := range dmiFiles {
+		if value, ok := readString("/sys/class/dmi/id/" + file); ok {
+			dmi[file] = value
+		}
+	}
+	dmiFound := len(dmi) != 0
+	productName := dmi["product_name"]
+	for _, file := range dmiFiles {
+		value, ok := dmi[file]
+		if !ok {
+			continue
+		}
+		vendor, cloud := matchDMIVendor(value)
+		// a cloud marker needs the hypervisor bit, or an instance type
+		// other than a bare-metal one
+		if cloud && !hypervisor && (productName == "" || strings.HasSuffix(productName, ".metal")) {
+			continue
+		}
+		if vendor != "" {
+			hypervisor = true
+			v.Sources = append(v.Sources, "dmi:"+value)
+			votes[vendor]++
+			// several DMI fields usually name the same vendor, only count
+			// DMI as a single source
+			break
+		}
+	}
+
+	// pick the vendor with the most votes, preferring the one reported by
+	// CPUID in case of a tie
+	vendors := make([]string, 0, len(votes))
+	for vendor := range votes {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+	bestVotes := 0
+	for _, vendor := range vendors {
+		if n := votes[vendor]; n > bestVotes || (n == bestVotes && vendor == cpuidVendor) {
+			v.HypervisorVendor = vendor
+			bestVotes = n
+		}
+	}
+
+	containerRuntime, containerSources := detectContainer(v)
+	v.ContainerRuntime = containerRuntime
+
+	switch {
+	case v.ContainerRuntime != "":
+		v.Type = VirtualizationContainer
+		v.Confidence = confidence(containerSources)
+	case hypervisor:
+		v.Type = VirtualizationFull
+		// Xen PV guests run without hardware virtualization, and do not
+		// get the CPUID hypervisor leaves
+		if v.HypervisorVendor == "xen" && (guestType == "PV" || (guestType == "" && cpuidVendor == "")) {
+			v.Type = VirtualizationPara
+		}
+		v.Confidence = confidence(bestVotes)
+	default:
+		// the absence of evidence is more convincing when the sources that
+		// would have provided it were available
+		v.Type = VirtualizationNone
+		available := 0
+		if cpuidFunc != nil {
+			available++
+		}
+		if dmiFound {
+			available++
+		}
+		v.Confidence = confidence(available)
+	}
+
+	return v
+}
+
+// matchDMIVendor returns the hypervisor vendor matching the given DMI value,
+// or an empty string, and whether it was matched by a cloud marker
+func matchDMIVendor(value string) (string, bool) {
+	for _, m := range dmiVendors {
+		if strings.Contains(value, m.marker) {
+			return m.vendor, m.cloud
+		}
+	}
+	return "", false
+}
+
+// detectContainer returns the container runtime this process runs in (or an
+// empty string) and the number of sources which identified it, appending
+// them to v.Sources
+func detectContainer(v *Virtualization) (string, int) {
+	runtime := ""
+	sources := 0
+	found := func(source, name string) {
+		v.Sources = append(v.Sources, source)
+		sources++
+		if runtime == "" {
+			runtime = name
+		}
+	}
+
+	// PID 1 environment variables are only readable by root, so this is
+	// checked first as the most specific source
+	if environ, err := ioutil.ReadFile(prefix + "/proc/1/environ"); err == nil {
+		for _, env := range bytes.Split(environ, []byte{0}) {
+			if name := strings.TrimPrefix(string(env), "container="); name != string(env) && name != "" {
+				found("environ:container="+name, name)
+				break
+			}
+		}
+	}
+
+	if _, err := os.Stat(prefix + "/.dockerenv"); err == nil {
+		found("file:/.dockerenv", "docker")
+	}
+	if _, err := os.Stat(prefix + "/run/.containerenv"); err == nil {
+		found("file:/run/.containerenv", "podman")
+	}
+
+	if cgroups, err := ioutil.ReadFile(prefix + "/proc/1/cgroup"); err == nil {
+	lines:
+		for _, line := range strings.Split(string(cgroups), "\n") {
+			// lines use the format `hierarchy-ID:controller-list:cgroup-path`
+			fields := strings.SplitN(line, ":", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			for _, m := range cgroupContainerMarkers {
+				if strings.Contains(fields[2], m.marker) {
+					found("cgroup:"+fields[2], m.runtime)
+					break lines
+				}
+			}
+		}
+	}
+
+	return runtime, sources
+}
+
+// confidence converts a number of concurring sources into a confidence level
+func confidence(sources int) string {
+	switch {
+	case sources >= 2:
+		return "high"
+	case sources == 1:
+		return "medium"
+	default:
+		return "low"
+	}
+}
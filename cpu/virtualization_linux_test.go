@@ -0,0 +1,156 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// withCPUID replaces cpuidFunc with a function returning the given registers
+// (EAX, EBX, ECX, EDX) for each {leaf, subleaf}, and zeros otherwise.  A nil
+// map disables CPUID altogether.
+func withCPUID(t *testing.T, leaves map[[2]uint32][4]uint32) {
+	oldCpuidFunc := cpuidFunc
+	t.Cleanup(func() { cpuidFunc = oldCpuidFunc })
+
+	if leaves == nil {
+		cpuidFunc = nil
+		return
+	}
+	cpuidFunc = func(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32) {
+		regs := leaves[[2]uint32{eaxArg, ecxArg}]
+		return regs[0], regs[1], regs[2], regs[3]
+	}
+}
+
+// stringRegisters encodes a 12-character string the way CPUID returns it in
+// three registers
+func stringRegisters(s string) [3]uint32 {
+	var regs [3]uint32
+	for i := 0; i < 12; i++ {
+		regs[i/4] |= uint32(s[i]) << (8 * (i % 4))
+	}
+	return regs
+}
+
+func TestVirtualizationKVM(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo":                  "processor\t: 0\nflags\t\t: fpu vme de pse hypervisor lahf_lm\n",
+		"sys/class/dmi/id/product_name": "KVM\n",
+		"sys/class/dmi/id/sys_vendor":   "QEMU\n",
+		"proc/1/cgroup":                 "0::/init.scope\n",
+	})
+	sig := stringRegisters("KVMKVMKVM\x00\x00\x00")
+	withCPUID(t, map[[2]uint32][4]uint32{
+		{1, 0}:                   {0, 0, 1 << 31, 0},
+		{cpuidHypervisorLeaf, 0}: {0x40000001, sig[0], sig[1], sig[2]},
+	})
+
+	require.Equal(t, &Virtualization{
+		Type:             VirtualizationFull,
+		HypervisorVendor: "kvm",
+		Confidence:       "high",
+		Sources:          []string{"cpuinfo:hypervisor", "cpuid:KVMKVMKVM", "dmi:KVM"},
+	}, getVirtualization())
+}
+
+func TestVirtualizationBareMetal(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo":                  "processor\t: 0\nflags\t\t: fpu vme de pse lahf_lm\n",
+		"sys/class/dmi/id/product_name": "PowerEdge R640\n",
+		"sys/class/dmi/id/sys_vendor":   "Dell Inc.\n",
+		"sys/class/dmi/id/bios_vendor":  "Dell Inc.\n",
+	})
+	withCPUID(t, map[[2]uint32][4]uint32{})
+
+	require.Equal(t, &Virtualization{
+		Type:       VirtualizationNone,
+		Confidence: "high",
+		Sources:    []string{},
+	}, getVirtualization())
+}
+
+func TestVirtualizationEC2(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo":                  "processor\t: 0\nflags\t\t: fpu vme de pse lahf_lm\n",
+		"sys/class/dmi/id/product_name": "m5.large\n",
+		"sys/class/dmi/id/sys_vendor":   "Amazon EC2\n",
+		"sys/class/dmi/id/bios_vendor":  "Amazon EC2\n",
+	})
+	withCPUID(t, map[[2]uint32][4]uint32{})
+
+	require.Equal(t, &Virtualization{
+		Type:             VirtualizationFull,
+		HypervisorVendor: "kvm",
+		Confidence:       "medium",
+		Sources:          []string{"dmi:Amazon EC2"},
+	}, getVirtualization())
+}
+
+func TestVirtualizationEC2Metal(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo":                  "processor\t: 0\nflags\t\t: fpu vme de pse lahf_lm\n",
+		"sys/class/dmi/id/product_name": "m5.metal\n",
+		"sys/class/dmi/id/sys_vendor":   "Amazon EC2\n",
+		"sys/class/dmi/id/bios_vendor":  "Amazon EC2\n",
+	})
+	withCPUID(t, map[[2]uint32][4]uint32{})
+
+	require.Equal(t, &Virtualization{
+		Type:       VirtualizationNone,
+		Confidence: "high",
+		Sources:    []string{},
+	}, getVirtualization())
+}
+
+func TestVirtualizationXenPV(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/hypervisor/type":       "xen\n",
+		"sys/hypervisor/guest_type": "PV\n",
+	})
+	withCPUID(t, nil)
+
+	require.Equal(t, &Virtualization{
+		Type:             VirtualizationPara,
+		HypervisorVendor: "xen",
+		Confidence:       "medium",
+		Sources:          []string{"sys_hypervisor:xen"},
+	}, getVirtualization())
+}
+
+func TestVirtualizationContainer(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/cpuinfo":                  "processor\t: 0\nflags\t\t: fpu hypervisor\n",
+		"sys/class/dmi/id/product_name": "Google Compute Engine\n",
+		"proc/1/environ":                "PATH=/usr/bin\x00container=docker\x00HOME=/\x00",
+		"proc/1/cgroup": "12:memory:/kubepods/burstable/pod1234/abcd\n" +
+			"0::/kubepods/burstable/pod1234/abcd\n",
+	})
+	withCPUID(t, nil)
+
+	require.Equal(t, &Virtualization{
+		Type:             VirtualizationContainer,
+		HypervisorVendor: "kvm",
+		ContainerRuntime: "docker",
+		Confidence:       "high",
+		Sources: []string{
+			"cpuinfo:hypervisor",
+			"dmi:Google Compute Engine",
+			"environ:container=docker",
+			"cgroup:/kubepods/burstable/pod1234/abcd",
+		},
+	}, getVirtualization())
+}
@@ -0,0 +1,166 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+// Package disks regroups collecting information about the block devices and
+// disk hardware
+package disks
+
+import (
+	"flag"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// Disks is the Collector type of the disks package.
+type Disks struct{}
+
+// Disk describes a block device from /sys/block
+type Disk struct {
+	// Name is the name of the block device, eg. "sda" or "nvme0n1"
+	Name string `json:"name"`
+	// Device is the major:minor number of the device
+	Device string `json:"device"`
+	// SizeBytes is the size of the device
+	SizeBytes uint64 `json:"size_bytes"`
+	// LogicalSectorBytes is the smallest unit the device can address
+	LogicalSectorBytes uint64 `json:"logical_sector_bytes"`
+	// PhysicalSectorBytes is the smallest unit the device can write without
+	// a read-modify-write cycle
+	PhysicalSectorBytes uint64 `json:"physical_sector_bytes"`
+	// Rotational tells whether the device is a spinning disk
+	Rotational bool `json:"rotational"`
+	// Removable tells whether the media can be removed
+	Removable bool `json:"removable"`
+	// ReadOnly tells whether the device is read-only
+	ReadOnly bool `json:"read_only"`
+	// Scheduler is the selected IO scheduler, eg. "mq-deadline" or "none"
+	Scheduler string `json:"scheduler"`
+	// Model is the model of the disk
+	Model string `json:"model"`
+	// Vendor is the vendor of the disk (SCSI only, "ATA" for SATA disks)
+	Vendor string `json:"vendor"`
+	// Serial is the serial number of the disk
+	Serial string `json:"serial"`
+	// WWN is the World Wide Name of the disk, eg. "naa.5000c500a1b2c3d4" or
+	// "eui.0025388b91b3c4d5"
+	WWN string `json:"wwn"`
+	// Firmware is the firmware revision of the disk
+	Firmware string `json:"firmware"`
+	// Transport is how the disk is attached: "sata", "sas", "scsi", "nvme",
+	// "virtio", "xen", "usb", "mmc" or "iscsi", or an empty string for
+	// virtual devices (device-mapper, md, loop, zram)
+	Transport string `json:"transport"`
+	// Partitions lists the partitions of the disk
+	Partitions []Partition `json:"partitions"`
+	// Holders lists the devices built on top of this one, eg. "dm-0"
+	Holders []string `json:"holders"`
+	// Slaves lists the devices this one is built on, for device-mapper and
+	// md devices
+	Slaves []string `json:"slaves"`
+	// NVMe describes the controller and namespace of NVMe disks
+	NVMe *NVMeNamespace `json:"nvme,omitempty"`
+	// IO holds the IO statistics of the disk, when sampled
+	IO *DeviceIOStats `json:"io,omitempty"`
+}
+
+// Partition describes a partition of a disk
+type Partition struct {
+	// Name is the name of the block device, eg. "sda1"
+	Name string `json:"name"`
+	// Device is the major:minor number of the device
+	Device string `json:"device"`
+	// Number is the partition number
+	Number uint64 `json:"number"`
+	// StartBytes is the offset of the partition on the disk
+	StartBytes uint64 `json:"start_bytes"`
+	// SizeBytes is the size of the partition
+	SizeBytes uint64 `json:"size_bytes"`
+	// ReadOnly tells whether the partition is read-only
+	ReadOnly bool `json:"read_only"`
+	// Holders lists the devices built on top of this partition
+	Holders []string `json:"holders"`
+	// IO holds the IO statistics of the partition, when sampled
+	IO *DeviceIOStats `json:"io,omitempty"`
+}
+
+// NVMeNamespace describes an NVMe namespace and its controller, from
+// /sys/class/nvme
+type NVMeNamespace struct {
+	// Controller is the name of the controller, eg. "nvme0"
+	Controller string `json:"controller"`
+	// NamespaceID is the ID of the namespace on the controller
+	NamespaceID uint64 `json:"namespace_id"`
+	// Model is the model of the controller
+	Model string `json:"model"`
+	// Serial is the serial number of the controller
+	Serial string `json:"serial"`
+	// Firmware is the firmware revision of the controller
+	Firmware string `json:"firmware"`
+	// Transport is the NVMe transport: "pcie", "tcp", "rdma", "fc" or "loop"
+	Transport string `json:"transport"`
+	// State is the state of the controller, eg. "live"
+	State string `json:"state"`
+	// SubsystemNQN is the NVMe Qualified Name of the subsystem
+	SubsystemNQN string `json:"subsystem_nqn"`
+}
+
+const name = "disks"
+
+var options struct {
+	sampleWindow time.Duration
+}
+
+func init() {
+	flag.DurationVar(&options.sampleWindow, name+"-sample-window", 0, "Sample the disk IO statistics over this duration, eg. '1s' (Linux only, disabled when 0)")
+}
+
+// Name returns the name of the package
+func (disks *Disks) Name() string {
+	return name
+}
+
+// Collect collects the disks information.
+// Returns an object which can be converted to a JSON or an error if nothing could be collected.
+// Tries to collect as much information as possible.
+func (disks *Disks) Collect() (result interface{}, err error) {
+	d, _, err := Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if options.sampleWindow > 0 {
+		if stats, err := SampleIOStats(options.sampleWindow); err == nil {
+			attachIOStats(d, stats)
+		} else {
+			log.Warnf("[%s] could not sample disk IO statistics: %s", name, err)
+		}
+	}
+
+	return d, nil
+}
+
+// Get returns the block devices of the host, a list of warnings and an error. The method will try to collect as much
+// metadata as possible, an error is returned if nothing could be collected. The list of warnings contains errors if
+// some metadata could not be collected.
+func Get() ([]Disk, []string, error) {
+	return getDisks()
+}
+
+// attachIOStats sets the IO statistics of each disk and partition from the
+// device of the same name
+func attachIOStats(disks []Disk, stats *IOStats) {
+	byName := make(map[string]*DeviceIOStats, len(stats.Devices))
+	for i := range stats.Devices {
+		byName[stats.Devices[i].Name] = &stats.Devices[i]
+	}
+
+	for i := range disks {
+		disks[i].IO = byName[disks[i].Name]
+		for j := range disks[i].Partitions {
+			disks[i].Partitions[j].IO = byName[disks[i].Partitions[j].Name]
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sysfs reports sizes and offsets in 512-byte sectors, whatever the sector
+// size of the device
+const sectorBytes = 512
+
+// nvmeNamespaceRegex recognizes NVMe namespaces, eg. `nvme0n1`, capturing the
+// controller number (`nvme0c1n1` is a path of a multipath namespace)
+var nvmeNamespaceRegex = regexp.MustCompile(`^nvme([0-9]+)(?:c[0-9]+)?n[0-9]+$`)
+
+// getDisks walks /sys/block, in which /sys/class/block links each disk, and
+// returns the disks with their partitions.  Devices with a size of 0 (unused
+// loop, ram and nbd devices) are skipped.
+func getDisks() ([]Disk, []string, error) {
+	names := listDir("/sys/block")
+	if len(names) == 0 && !exists("/sys/block") {
+		return nil, nil, errors.New("could not read /sys/block")
+	}
+
+	warnings := []string{}
+	disks := []Disk{}
+	for _, name := range names {
+		dir := "/sys/block/" + name + "/"
+		sectors, ok := readUint(dir + "size")
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("could not read the size of %s", name))
+			continue
+		}
+		if sectors == 0 {
+			continue
+		}
+		disks = append(disks, getDisk(name, sectors))
+	}
+
+	return disks, warnings, nil
+}
+
+// getDisk reads the attributes of the disk with the given name and size
+func getDisk(name string, sectors uint64) Disk {
+	dir := "/sys/block/" + name + "/"
+	disk := Disk{
+		Name:       name,
+		SizeBytes:  sectors * sectorBytes,
+		Rotational: readBool(dir + "queue/rotational"),
+		Removable:  readBool(dir + "removable"),
+		ReadOnly:   readBool(dir + "ro"),
+		Partitions: []Partition{},
+		Holders:    listDir(dir + "holders"),
+		Slaves:     listDir(dir + "slaves"),
+	}
+	disk.Device, _ = readString(dir + "dev")
+	disk.LogicalSectorBytes, _ = readUint(dir + "queue/logical_block_size")
+	disk.PhysicalSectorBytes, _ = readUint(dir + "queue/physical_block_size")
+	if scheduler, ok := readString(dir + "queue/scheduler"); ok {
+		disk.Scheduler = selectedMode(scheduler)
+	}
+
+	disk.Transport = transport(name)
+	if disk.Transport != "nvme" && exists(dir+"device/model") {
+		// SCSI disks, including SATA disks behind libata
+		disk.Model, _ = readString(dir + "device/model")
+		disk.Vendor, _ = readString(dir + "device/vendor")
+		disk.Firmware, _ = readString(dir + "device/rev")
+	}
+	disk.Serial = readSerial(dir)
+	if wwn, ok := readString(dir + "wwid"); ok {
+		disk.WWN = wwn
+	} else {
+		disk.WWN, _ = readString(dir + "device/wwid")
+	}
+
+	if submatches := nvmeNamespaceRegex.FindStringSubmatch(name); submatches != nil {
+		if nvme, ok := getNVMeNamespace("nvme"+submatches[1], name); ok {
+			disk.NVMe = nvme
+			disk.Model = nvme.Model
+			disk.Serial = nvme.Serial
+			disk.Firmware = nvme.Firmware
+		}
+	}
+
+	for _, entry := range listDir(dir) {
+		if number, ok := readUint(dir + entry + "/partition"); ok {
+			disk.Partitions = append(disk.Partitions, getPartition(dir+entry+"/", entry, number))
+		}
+	}
+
+	return disk
+}
+
+// getPartition reads the attributes of a partition in the given directory
+func getPartition(dir string, name string, number uint64) Partition {
+	p := Partition{
+		Name:     name,
+		Number:   number,
+		ReadOnly: readBool(dir + "ro"),
+		Holders:  listDir(dir + "holders"),
+	}
+	p.Device, _ = readString(dir + "dev")
+	if start, ok := readUint(dir + "start"); ok {
+		p.StartBytes = start * sectorBytes
+	}
+	if size, ok := readUint(dir + "size"); ok {
+		p.SizeBytes = size * sectorBytes
+	}
+	return p
+}
+
+// transport guesses how the disk with the given name is attached, from its
+// name and from the path of its device in /sys/devices
+func transport(name string) string {
+	switch {
+	case nvmeNamespaceRegex.MatchString(name):
+		return "nvme"
+	case strings.HasPrefix(name, "vd"):
+		return "virtio"
+	case strings.HasPrefix(name, "xvd"):
+		return "xen"
+	case strings.HasPrefix(name, "mmcblk"):
+		return "mmc"
+	}
+
+	dir := "/sys/block/" + name + "/device/"
+	if !exists(dir) {
+		// device-mapper, md, loop, zram, ...
+		return ""
+	}
+
+	devicePath, _ := filepath.EvalSymlinks(prefix + dir)
+	vendor, _ := readString(dir + "vendor")
+	switch {
+	case strings.Contains(devicePath, "/usb"):
+		return "usb"
+	case strings.Contains(devicePath, "/virtio"):
+		// virtio-scsi
+		return "virtio"
+	case exists(dir + "sas_address"):
+		return "sas"
+	case vendor == "ATA" || strings.Contains(devicePath, "/ata"):
+		return "sata"
+	case strings.Contains(devicePath, "/session"):
+		return "iscsi"
+	}
+	return "scsi"
+}
+
+// readSerial reads the serial number of the disk in the given directory,
+// from the attributes of virtio and SCSI disks, or from the unit serial
+// number VPD page
+func readSerial(dir string) string {
+	for _, file := range []string{"serial", "device/serial"} {
+		if serial, ok := readString(dir + file); ok && serial != "" {
+			return serial
+		}
+	}
+
+	// the VPD page 0x80 is a 4-byte header followed by the serial number
+	if page, ok := readString(dir + "device/vpd_pg80"); ok && len(page) > 4 {
+		return strings.Trim(page[4:], " \x00")
+	}
+	return ""
+}
+
+// getNVMeNamespace reads the controller attributes and the namespace ID of
+// the given namespace from /sys/class/nvme/<controller>
+func getNVMeNamespace(controller string, namespace string) (*NVMeNamespace, bool) {
+	dir := "/sys/class/nvme/" + controller + "/"
+	if !exists(dir) {
+		return nil, false
+	}
+
+	nvme := &NVMeNamespace{Controller: controller}
+	nsid, ok := readUint(dir + namespace + "/nsid")
+	if !ok {
+		// the namespace is not below its controller with native multipath
+		nsid, _ = readUint("/sys/block/" + namespace + "/nsid")
+	}
+	nvme.NamespaceID = nsid
+	nvme.Model, _ = readString(dir + "model")
+	nvme.Serial, _ = readString(dir + "serial")
+	nvme.Firmware, _ = readString(dir + "firmware_rev")
+	nvme.Transport, _ = readString(dir + "transport")
+	nvme.State, _ = readString(dir + "state")
+	nvme.SubsystemNQN, _ = readString(dir + "subsysnqn")
+	return nvme, true
+}
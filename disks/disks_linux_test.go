@@ -0,0 +1,147 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetDisks(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		// a SATA disk with a partition used by LVM
+		"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/model":    "Samsung SSD 870\n",
+		"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/vendor":   "ATA     \n",
+		"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/rev":      "2B6Q\n",
+		"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/wwid":     "naa.5002538f4123abcd\n",
+		"sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/vpd_pg80": "\x00\x80\x00\x14S5Y2NG0R123456A     ",
+		"sys/block/sda/dev":                       "8:0\n",
+		"sys/block/sda/size":                      "1953525168\n",
+		"sys/block/sda/removable":                 "0\n",
+		"sys/block/sda/ro":                        "0\n",
+		"sys/block/sda/queue/rotational":          "0\n",
+		"sys/block/sda/queue/logical_block_size":  "512\n",
+		"sys/block/sda/queue/physical_block_size": "4096\n",
+		"sys/block/sda/queue/scheduler":           "none [mq-deadline] kyber bfq\n",
+		"sys/block/sda/sda1/partition":            "1\n",
+		"sys/block/sda/sda1/dev":                  "8:1\n",
+		"sys/block/sda/sda1/start":                "2048\n",
+		"sys/block/sda/sda1/size":                 "1953523120\n",
+		"sys/block/sda/sda1/ro":                   "0\n",
+		"sys/block/sda/sda1/holders/dm-0":         "",
+		// an NVMe namespace
+		"sys/block/nvme0n1/dev":                       "259:0\n",
+		"sys/block/nvme0n1/size":                      "3907029168\n",
+		"sys/block/nvme0n1/wwid":                      "eui.0025388b91b3c4d5\n",
+		"sys/block/nvme0n1/queue/rotational":          "0\n",
+		"sys/block/nvme0n1/queue/logical_block_size":  "4096\n",
+		"sys/block/nvme0n1/queue/physical_block_size": "4096\n",
+		"sys/block/nvme0n1/queue/scheduler":           "[none] mq-deadline\n",
+		"sys/class/nvme/nvme0/model":                  "Samsung SSD 980 PRO 2TB                 \n",
+		"sys/class/nvme/nvme0/serial":                 "S6B0NL0T123456      \n",
+		"sys/class/nvme/nvme0/firmware_rev":           "5B2QGXA7\n",
+		"sys/class/nvme/nvme0/transport":              "pcie\n",
+		"sys/class/nvme/nvme0/state":                  "live\n",
+		"sys/class/nvme/nvme0/subsysnqn":              "nqn.1994-11.com.samsung:nvme:980PRO:M.2:S6B0NL0T123456\n",
+		"sys/class/nvme/nvme0/nvme0n1/nsid":           "1\n",
+		// a device-mapper device on top of sda1
+		"sys/block/dm-0/dev":             "253:0\n",
+		"sys/block/dm-0/size":            "1953523120\n",
+		"sys/block/dm-0/slaves/sda1":     "",
+		"sys/block/dm-0/queue/scheduler": "none\n",
+		// a virtio disk
+		"sys/block/vda/dev":    "252:0\n",
+		"sys/block/vda/size":   "41943040\n",
+		"sys/block/vda/serial": "BHYVE-1234\n",
+		// an unused loop device
+		"sys/block/loop0/dev":  "7:0\n",
+		"sys/block/loop0/size": "0\n",
+	})
+	require.NoError(t, os.Symlink(
+		filepath.Join(prefix, "sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0"),
+		filepath.Join(prefix, "sys/block/sda/device")))
+
+	disks, warnings, err := Get()
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.Equal(t, []Disk{
+		{
+			Name:       "dm-0",
+			Device:     "253:0",
+			SizeBytes:  1953523120 * 512,
+			Scheduler:  "none",
+			Partitions: []Partition{},
+			Holders:    []string{},
+			Slaves:     []string{"sda1"},
+		},
+		{
+			Name:                "nvme0n1",
+			Device:              "259:0",
+			SizeBytes:           3907029168 * 512,
+			LogicalSectorBytes:  4096,
+			PhysicalSectorBytes: 4096,
+			Scheduler:           "none",
+			Model:               "Samsung SSD 980 PRO 2TB",
+			Serial:              "S6B0NL0T123456",
+			WWN:                 "eui.0025388b91b3c4d5",
+			Firmware:            "5B2QGXA7",
+			Transport:           "nvme",
+			Partitions:          []Partition{},
+			Holders:             []string{},
+			Slaves:              []string{},
+			NVMe: &NVMeNamespace{
+				Controller:   "nvme0",
+				NamespaceID:  1,
+				Model:        "Samsung SSD 980 PRO 2TB",
+				Serial:       "S6B0NL0T123456",
+				Firmware:     "5B2QGXA7",
+				Transport:    "pcie",
+				State:        "live",
+				SubsystemNQN: "nqn.1994-11.com.samsung:nvme:980PRO:M.2:S6B0NL0T123456",
+			},
+		},
+		{
+			Name:                "sda",
+			Device:              "8:0",
+			SizeBytes:           1953525168 * 512,
+			LogicalSectorBytes:  512,
+			PhysicalSectorBytes: 4096,
+			Scheduler:           "mq-deadline",
+			Model:               "Samsung SSD 870",
+			Vendor:              "ATA",
+			Serial:              "S5Y2NG0R123456A",
+			WWN:                 "naa.5002538f4123abcd",
+			Firmware:            "2B6Q",
+			Transport:           "sata",
+			Partitions: []Partition{{
+				Name:       "sda1",
+				Device:     "8:1",
+				Number:     1,
+				StartBytes: 2048 * 512,
+				SizeBytes:  1953523120 * 512,
+				Holders:    []string{"dm-0"},
+			}},
+			Holders: []string{},
+			Slaves:  []string{},
+		},
+		{
+			Name:       "vda",
+			Device:     "252:0",
+			SizeBytes:  41943040 * 512,
+			Serial:     "BHYVE-1234",
+			Transport:  "virtio",
+			Partitions: []Partition{},
+			Holders:    []string{},
+			Slaves:     []string{},
+		},
+	}, disks)
+}
@@ -0,0 +1,31 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package disks
+
+import (
+	"errors"
+	"time"
+)
+
+// getDisks is a no-op: block devices are only read from the Linux sysfs
+func getDisks() ([]Disk, []string, error) {
+	return nil, nil, nil
+}
+
+// GetStorageStacks returns an error: block devices are only read from the
+// Linux sysfs
+func GetStorageStacks() (map[string]*StackNode, error) {
+	return nil, errors.New("storage stacks are only collected on Linux")
+}
+
+// SampleIOStats returns an error: IO statistics are only read from the Linux
+// /proc/diskstats
+func SampleIOStats(window time.Duration) (*IOStats, error) {
+	return nil, errors.New("disk IO sampling is only supported on Linux")
+}
@@ -0,0 +1,51 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+// IOStats holds the IO statistics of the block devices sampled over a window
+// of time, as returned by SampleIOStats
+type IOStats struct {
+	// WindowSeconds is the duration of the sample in seconds
+	WindowSeconds float64 `json:"window_seconds"`
+	// Devices lists the statistics of each block device which has done IO
+	// since boot
+	Devices []DeviceIOStats `json:"devices"`
+}
+
+// DeviceIOStats holds the IO statistics of a block device during a sample,
+// computed like `iostat -x` does
+type DeviceIOStats struct {
+	// Name is the name of the block device, eg. "sda1"
+	Name string `json:"name"`
+	// Device is the major:minor number of the device
+	Device string `json:"device"`
+	// Disk is the disk holding a partition, or the name of the device itself
+	// for disks
+	Disk string `json:"disk"`
+	// MountPoints lists where the filesystem of the device is mounted
+	MountPoints []string `json:"mount_points"`
+
+	// ReadsPerSecond is the number of completed reads per second
+	ReadsPerSecond float64 `json:"reads_per_second"`
+	// WritesPerSecond is the number of completed writes per second
+	WritesPerSecond float64 `json:"writes_per_second"`
+	// ReadBytesPerSecond is the read throughput
+	ReadBytesPerSecond float64 `json:"read_bytes_per_second"`
+	// WriteBytesPerSecond is the write throughput
+	WriteBytesPerSecond float64 `json:"write_bytes_per_second"`
+	// ReadLatencyMilliseconds is the average time reads took to complete,
+	// including queueing
+	ReadLatencyMilliseconds float64 `json:"read_latency_ms"`
+	// WriteLatencyMilliseconds is the average time writes took to complete,
+	// including queueing
+	WriteLatencyMilliseconds float64 `json:"write_latency_ms"`
+	// UtilizationPercent is the share of time the device had IO in flight
+	UtilizationPercent float64 `json:"utilization_percent"`
+	// QueueDepth is the average number of requests in flight
+	QueueDepth float64 `json:"queue_depth"`
+	// InFlight is the number of requests in flight at the end of the sample
+	InFlight uint64 `json:"in_flight"`
+}
@@ -0,0 +1,209 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// diskCounters holds the cumulative counters of a block device, as read from
+// a line of /proc/diskstats
+type diskCounters struct {
+	name   string
+	device string
+	// completed reads, sectors read and milliseconds spent reading
+	reads, readSectors, readMs uint64
+	// completed writes, sectors written and milliseconds spent writing
+	writes, writeSectors, writeMs uint64
+	// requests in flight, milliseconds spent with requests in flight, and
+	// milliseconds spent by all requests (weighting the time by the number
+	// of requests in flight)
+	inFlight, ioMs, weightedMs uint64
+}
+
+// SampleIOStats reads /proc/diskstats twice, window apart, and returns the IO
+// statistics of each block device over that window.  The devices are joined
+// with their disk from /sys/block and with their mount points.
+func SampleIOStats(window time.Duration) (*IOStats, error) {
+	before, err := readDiskStats()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	time.Sleep(window)
+
+	after, err := readDiskStats()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeIOStats(before, after, time.Since(start))
+
+	devices := indexBlockDevices()
+	mountPoints := readMountPoints()
+	for i := range stats.Devices {
+		d := &stats.Devices[i]
+		d.Disk = d.Name
+		if device, ok := devices[d.Name]; ok && device.disk != "" {
+			d.Disk = device.disk
+		}
+		if mountPoints[d.Device] != nil {
+			d.MountPoints = mountPoints[d.Device]
+		}
+	}
+
+	return stats, nil
+}
+
+// readDiskStats reads the counters of each device from /proc/diskstats:
+//
+//	8       0 sda 58302 14128 4203834 25637 96581 80424 5348170 93740 0 62136 129964 0 0 0 0 1820 10586
+func readDiskStats() ([]diskCounters, error) {
+	file, err := os.Open(prefix + "/proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var result []diskCounters
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+
+		// the discard and flush counters of recent kernels are not used
+		var values [11]uint64
+		for i := range values {
+			values[i], err = strconv.ParseUint(fields[i+3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse /proc/diskstats line %q: %s", scanner.Text(), err)
+			}
+		}
+
+		result = append(result, diskCounters{
+			name:         fields[2],
+			device:       fields[0] + ":" + fields[1],
+			reads:        values[0],
+			readSectors:  values[2],
+			readMs:       values[3],
+			writes:       values[4],
+			writeSectors: values[6],
+			writeMs:      values[7],
+			inFlight:     values[8],
+			ioMs:         values[9],
+			weightedMs:   values[10],
+		})
+	}
+
+	if scanner.Err() != nil {
+		return nil, scanner.Err()
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no device found in /proc/diskstats")
+	}
+
+	return result, nil
+}
+
+// computeIOStats returns the IO statistics between two reads of
+// /proc/diskstats taken elapsed apart.  Devices which are not present in both
+// reads, or which never did any IO (eg. unused loop and ram devices), are
+// skipped.
+func computeIOStats(before, after []diskCounters, elapsed time.Duration) *IOStats {
+	stats := &IOStats{
+		WindowSeconds: elapsed.Seconds(),
+		Devices:       []DeviceIOStats{},
+	}
+
+	previous := make(map[string]diskCounters, len(before))
+	for _, c := range before {
+		previous[c.name] = c
+	}
+
+	for _, c := range after {
+		prev, ok := previous[c.name]
+		if !ok || c.reads+c.writes == 0 {
+			continue
+		}
+		stats.Devices = append(stats.Devices, deviceIOStats(prev, c, elapsed))
+	}
+
+	return stats
+}
+
+// deviceIOStats returns the statistics of a device between two reads of its
+// counters, as `iostat -x` computes them
+func deviceIOStats(before, after diskCounters, elapsed time.Duration) DeviceIOStats {
+	d := DeviceIOStats{
+		Name:        after.name,
+		Device:      after.device,
+		MountPoints: []string{},
+		InFlight:    after.inFlight,
+	}
+
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return d
+	}
+
+	// the counters wrap on 32-bit kernels, and are reset when a device is
+	// removed and added again
+	delta := func(b, a uint64) float64 {
+		if a < b {
+			return 0
+		}
+		return float64(a - b)
+	}
+	reads := delta(before.reads, after.reads)
+	writes := delta(before.writes, after.writes)
+
+	d.ReadsPerSecond = reads / seconds
+	d.WritesPerSecond = writes / seconds
+	d.ReadBytesPerSecond = delta(before.readSectors, after.readSectors) * sectorBytes / seconds
+	d.WriteBytesPerSecond = delta(before.writeSectors, after.writeSectors) * sectorBytes / seconds
+	if reads > 0 {
+		d.ReadLatencyMilliseconds = delta(before.readMs, after.readMs) / reads
+	}
+	if writes > 0 {
+		d.WriteLatencyMilliseconds = delta(before.writeMs, after.writeMs) / writes
+	}
+
+	ms := seconds * 1000
+	d.UtilizationPercent = delta(before.ioMs, after.ioMs) * 100 / ms
+	if d.UtilizationPercent > 100 {
+		// the kernel accounts the time of a request when it completes
+		d.UtilizationPercent = 100
+	}
+	d.QueueDepth = delta(before.weightedMs, after.weightedMs) / ms
+
+	return d
+}
+
+// readMountPoints returns the mount points of each device, keyed by
+// major:minor number, or an empty map if mountinfo cannot be read
+func readMountPoints() map[string][]string {
+	mountPoints := map[string][]string{}
+	mounts, err := utils.ReadMountInfo(prefix + "/proc/self/mountinfo")
+	if err != nil {
+		return mountPoints
+	}
+	for _, mount := range mounts {
+		device := fmt.Sprintf("%d:%d", mount.Major, mount.Minor)
+		mountPoints[device] = append(mountPoints[device], mount.MountPoint)
+	}
+	return mountPoints
+}
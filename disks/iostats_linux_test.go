@@ -0,0 +1,111 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestReadDiskStats(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/diskstats": "   7       0 loop0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n" +
+			"   8       0 sda 58302 14128 4203834 25637 96581 80424 5348170 93740 2 62136 129964 0 0 0 0 1820 10586\n" +
+			"   8       1 sda1 1200 0 96000 800 300 0 4800 150 0 700 950\n" +
+			"   8       2 sda2\n",
+	})
+
+	counters, err := readDiskStats()
+	require.NoError(t, err)
+	require.Len(t, counters, 3)
+	assert.Equal(t, diskCounters{
+		name:         "sda",
+		device:       "8:0",
+		reads:        58302,
+		readSectors:  4203834,
+		readMs:       25637,
+		writes:       96581,
+		writeSectors: 5348170,
+		writeMs:      93740,
+		inFlight:     2,
+		ioMs:         62136,
+		weightedMs:   129964,
+	}, counters[1])
+	assert.Equal(t, "8:1", counters[2].device)
+	assert.Equal(t, uint64(950), counters[2].weightedMs)
+}
+
+func TestComputeIOStats(t *testing.T) {
+	before := []diskCounters{
+		{name: "loop0", device: "7:0"},
+		{name: "sda", device: "8:0", reads: 1000, readSectors: 80000, readMs: 2000, writes: 500, writeSectors: 40000, writeMs: 5000, ioMs: 10000, weightedMs: 20000},
+		{name: "sdb", device: "8:16", reads: 10, writes: 10},
+	}
+	after := []diskCounters{
+		{name: "loop0", device: "7:0"},
+		// 200 reads of 8 sectors taking 2ms each, 100 writes of 16 sectors
+		// taking 5ms each, the disk being busy half of the window
+		{name: "sda", device: "8:0", reads: 1200, readSectors: 81600, readMs: 2400, writes: 600, writeSectors: 41600, writeMs: 5500, inFlight: 3, ioMs: 11000, weightedMs: 21800},
+		// counters reset
+		{name: "sdb", device: "8:16", reads: 5, writes: 0},
+		// hot-plugged during the sample
+		{name: "sdc", device: "8:32", reads: 10},
+	}
+
+	stats := computeIOStats(before, after, 2*time.Second)
+	assert.Equal(t, 2.0, stats.WindowSeconds)
+	require.Len(t, stats.Devices, 2)
+
+	sda := stats.Devices[0]
+	assert.Equal(t, "sda", sda.Name)
+	assert.Equal(t, "8:0", sda.Device)
+	assert.InDelta(t, 100.0, sda.ReadsPerSecond, 1e-9)
+	assert.InDelta(t, 50.0, sda.WritesPerSecond, 1e-9)
+	assert.InDelta(t, 409600.0, sda.ReadBytesPerSecond, 1e-9)
+	assert.InDelta(t, 409600.0, sda.WriteBytesPerSecond, 1e-9)
+	assert.InDelta(t, 2.0, sda.ReadLatencyMilliseconds, 1e-9)
+	assert.InDelta(t, 5.0, sda.WriteLatencyMilliseconds, 1e-9)
+	assert.InDelta(t, 50.0, sda.UtilizationPercent, 1e-9)
+	assert.InDelta(t, 0.9, sda.QueueDepth, 1e-9)
+	assert.Equal(t, uint64(3), sda.InFlight)
+
+	sdb := stats.Devices[1]
+	assert.Equal(t, "sdb", sdb.Name)
+	assert.Zero(t, sdb.ReadsPerSecond)
+	assert.Zero(t, sdb.ReadLatencyMilliseconds)
+}
+
+func TestSampleIOStats(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/diskstats":               "   8       0 sda 100 0 800 50 10 0 80 20 0 60 70\n   8       1 sda1 90 0 720 45 10 0 80 20 0 55 65\n",
+		"proc/self/mountinfo":          "25 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n26 25 8:1 /srv /srv rw,relatime shared:1 - ext4 /dev/sda1 rw\n",
+		"sys/block/sda/dev":            "8:0\n",
+		"sys/block/sda/sda1/dev":       "8:1\n",
+		"sys/block/sda/sda1/partition": "1\n",
+	})
+
+	stats, err := SampleIOStats(time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, stats.Devices, 2)
+	assert.Equal(t, "sda", stats.Devices[0].Disk)
+	assert.Equal(t, []string{}, stats.Devices[0].MountPoints)
+	assert.Equal(t, "sda", stats.Devices[1].Disk)
+	assert.Equal(t, []string{"/", "/srv"}, stats.Devices[1].MountPoints)
+
+	disks := []Disk{{Name: "sda", Partitions: []Partition{{Name: "sda1"}}}}
+	attachIOStats(disks, stats)
+	require.NotNil(t, disks[0].IO)
+	assert.Equal(t, "8:0", disks[0].IO.Device)
+	require.NotNil(t, disks[0].Partitions[0].IO)
+	assert.Equal(t, "8:1", disks[0].Partitions[0].IO.Device)
+}
@@ -0,0 +1,78 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+// StackNode is a layer of the storage stack under a block device, linked to
+// the layers it is built on down to the physical disks
+type StackNode struct {
+	// Name is the name of the block device, eg. "dm-0" or "sda1"
+	Name string `json:"name"`
+	// Device is the major:minor number of the device
+	Device string `json:"device"`
+	// Type is the kind of layer: "disk", "partition", "lvm", "crypt",
+	// "multipath", "dm" for other device-mapper devices, or the RAID level
+	// of md devices (eg. "raid1")
+	Type string `json:"type"`
+	// SizeBytes is the size of the device
+	SizeBytes uint64 `json:"size_bytes"`
+	// Removable tells whether the disk is removable or attached over USB
+	// (disks only)
+	Removable bool `json:"removable"`
+	// DM describes device-mapper devices
+	DM *DeviceMapper `json:"dm,omitempty"`
+	// MD describes md RAID arrays
+	MD *RAID `json:"md,omitempty"`
+	// Lower lists the layers this device is built on: the disk of a
+	// partition, or the slaves of device-mapper and md devices
+	Lower []StackNode `json:"lower"`
+}
+
+// DeviceMapper describes a device-mapper device, from /sys/block/dm-N/dm
+type DeviceMapper struct {
+	// Name is the device-mapper name, as found in /dev/mapper
+	Name string `json:"name"`
+	// UUID is the device-mapper UUID, prefixed by the subsystem which
+	// created the device (eg. "LVM-", "CRYPT-LUKS2-", "mpath-")
+	UUID string `json:"uuid"`
+	// Suspended tells whether IO to the device is suspended
+	Suspended bool `json:"suspended"`
+	// VolumeGroup is the volume group of LVM logical volumes
+	VolumeGroup string `json:"volume_group,omitempty"`
+	// LogicalVolume is the name of LVM logical volumes
+	LogicalVolume string `json:"logical_volume,omitempty"`
+}
+
+// RAID describes an md RAID array, from /sys/block/mdN/md and /proc/mdstat
+type RAID struct {
+	// Level is the RAID level, eg. "raid1"
+	Level string `json:"level"`
+	// State is the state of the array, eg. "clean" or "active"
+	State string `json:"state"`
+	// RaidDisks is the number of devices in a fully functional array
+	RaidDisks uint64 `json:"raid_disks"`
+	// Degraded is the number of missing devices
+	Degraded uint64 `json:"degraded"`
+	// SyncAction is the current synchronisation action, eg. "idle" or
+	// "recover"
+	SyncAction string `json:"sync_action"`
+	// Status is the member status from /proc/mdstat, eg. "[2/1] [U_]"
+	Status string `json:"status"`
+	// Progress is the progress of the running resync or recovery from
+	// /proc/mdstat, eg. "recovery = 12.6%"
+	Progress string `json:"progress,omitempty"`
+	// Members lists the member devices of the array
+	Members []RAIDMember `json:"members"`
+}
+
+// RAIDMember is a member device of an md RAID array
+type RAIDMember struct {
+	// Name is the name of the block device, eg. "sda1"
+	Name string `json:"name"`
+	// Slot is the role of the device in the array, or "none" for spares
+	Slot string `json:"slot"`
+	// State is the state of the device, eg. "in_sync", "faulty" or "spare"
+	State string `json:"state"`
+}
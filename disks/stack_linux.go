@@ -0,0 +1,229 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxStackDepth bounds the recursion through the slaves of the devices, in
+// case sysfs reports a cycle
+const maxStackDepth = 16
+
+// mdstatArrayRegex recognizes the first line of an array in /proc/mdstat,
+// eg. `md0 : active raid1 sdb1[1] sda1[0]`
+var mdstatArrayRegex = regexp.MustCompile(`^(md[0-9a-z_]+) : `)
+
+// mdstatStatusRegex extracts the member status of an array, eg. `[2/1] [U_]`
+var mdstatStatusRegex = regexp.MustCompile(`\[[0-9]+/[0-9]+\] \[[U_]+\]`)
+
+// mdstatProgressRegex extracts the progress of a resync or recovery, eg.
+// `recovery = 12.6%`
+var mdstatProgressRegex = regexp.MustCompile(`(resync|recovery|reshape|check|repair) *= *[0-9.]+%`)
+
+// blockDevice locates a disk or partition in /sys/block
+type blockDevice struct {
+	// dir is the sysfs directory of the device
+	dir string
+	// disk is the disk holding a partition, empty for disks
+	disk string
+}
+
+// mdstatArray is the status of an array in /proc/mdstat
+type mdstatArray struct {
+	status   string
+	progress string
+}
+
+// GetStorageStacks returns the storage stack under each disk and partition
+// of /sys/block, keyed by major:minor number
+func GetStorageStacks() (map[string]*StackNode, error) {
+	devices := indexBlockDevices()
+	if len(devices) == 0 {
+		return nil, errors.New("no block device found in /sys/block")
+	}
+	mdstat := readMDStat()
+
+	stacks := map[string]*StackNode{}
+	for name := range devices {
+		node := buildStack(name, devices, mdstat, 0)
+		if node.Device != "" {
+			stacks[node.Device] = &node
+		}
+	}
+	return stacks, nil
+}
+
+// indexBlockDevices lists the disks of /sys/block and their partitions
+func indexBlockDevices() map[string]blockDevice {
+	devices := map[string]blockDevice{}
+	for _, disk := range listDir("/sys/block") {
+		dir := "/sys/block/" + disk + "/"
+		devices[disk] = blockDevice{dir: dir}
+		for _, entry := range listDir(dir) {
+			if exists(dir + entry + "/partition") {
+				devices[entry] = blockDevice{dir: dir + entry + "/", disk: disk}
+			}
+		}
+	}
+	return devices
+}
+
+// buildStack returns the stack under the device with the given name
+func buildStack(name string, devices map[string]blockDevice, mdstat map[string]mdstatArray, depth int) StackNode {
+	device := devices[name]
+	node := StackNode{Name: name, Type: "disk", Lower: []StackNode{}}
+	node.Device, _ = readString(device.dir + "dev")
+	if sectors, ok := readUint(device.dir + "size"); ok {
+		node.SizeBytes = sectors * sectorBytes
+	}
+	if depth >= maxStackDepth {
+		return node
+	}
+
+	if device.disk != "" {
+		node.Type = "partition"
+		node.Lower = append(node.Lower, buildStack(device.disk, devices, mdstat, depth+1))
+		return node
+	}
+
+	switch {
+	case exists(device.dir + "dm"):
+		node.DM = readDeviceMapper(device.dir + "dm/")
+		node.Type = deviceMapperType(node.DM.UUID)
+	case exists(device.dir + "md"):
+		node.MD = readRAID(device.dir+"md/", mdstat[name])
+		node.Type = node.MD.Level
+	case strings.HasPrefix(name, "loop"):
+		node.Type = "loop"
+	default:
+		node.Removable = readBool(device.dir+"removable") || transport(name) == "usb"
+	}
+
+	for _, slave := range listDir(device.dir + "slaves") {
+		if _, ok := devices[slave]; ok {
+			node.Lower = append(node.Lower, buildStack(slave, devices, mdstat, depth+1))
+		}
+	}
+	return node
+}
+
+// readDeviceMapper reads the attributes of a device-mapper device in the
+// given `dm` directory
+func readDeviceMapper(dir string) *DeviceMapper {
+	dm := &DeviceMapper{Suspended: readBool(dir + "suspended")}
+	dm.Name, _ = readString(dir + "name")
+	dm.UUID, _ = readString(dir + "uuid")
+	if strings.HasPrefix(dm.UUID, "LVM-") {
+		dm.VolumeGroup, dm.LogicalVolume = splitLVMName(dm.Name)
+	}
+	return dm
+}
+
+// deviceMapperType returns the kind of a device-mapper device from the
+// subsystem prefix of its UUID
+func deviceMapperType(uuid string) string {
+	switch {
+	case strings.HasPrefix(uuid, "LVM-"):
+		return "lvm"
+	case strings.HasPrefix(uuid, "CRYPT-"):
+		return "crypt"
+	case strings.HasPrefix(uuid, "mpath-"):
+		return "multipath"
+	case strings.HasPrefix(uuid, "part") && strings.Contains(uuid, "-mpath-"):
+		// a partition of a multipath device, created by kpartx
+		return "partition"
+	}
+	return "dm"
+}
+
+// splitLVMName splits the device-mapper name of a logical volume into its
+// volume group and logical volume names.  Both are joined by a single dash,
+// dashes within them being doubled: `vg--data-lv--root` is the `lv-root`
+// volume of the `vg-data` group.
+func splitLVMName(name string) (string, string) {
+	for i := 0; i < len(name); i++ {
+		if name[i] != '-' {
+			continue
+		}
+		if i+1 < len(name) && name[i+1] == '-' {
+			i++
+			continue
+		}
+		return strings.ReplaceAll(name[:i], "--", "-"), strings.ReplaceAll(name[i+1:], "--", "-")
+	}
+	return "", strings.ReplaceAll(name, "--", "-")
+}
+
+// readRAID reads the attributes of an md array in the given `md` directory,
+// completed by its status in /proc/mdstat
+func readRAID(dir string, status mdstatArray) *RAID {
+	raid := &RAID{
+		Status:   status.status,
+		Progress: status.progress,
+		Members:  []RAIDMember{},
+	}
+	raid.Level, _ = readString(dir + "level")
+	raid.State, _ = readString(dir + "array_state")
+	raid.RaidDisks, _ = readUint(dir + "raid_disks")
+	raid.Degraded, _ = readUint(dir + "degraded")
+	raid.SyncAction, _ = readString(dir + "sync_action")
+
+	for _, entry := range listDir(dir) {
+		if !strings.HasPrefix(entry, "dev-") {
+			continue
+		}
+		member := RAIDMember{Name: strings.TrimPrefix(entry, "dev-")}
+		member.Slot, _ = readString(dir + entry + "/slot")
+		member.State, _ = readString(dir + entry + "/state")
+		raid.Members = append(raid.Members, member)
+	}
+	return raid
+}
+
+// readMDStat reads the member status and the sync progress of each array
+// from /proc/mdstat:
+//
+//	md0 : active raid1 sdb1[1] sda1[0]
+//	      1953382464 blocks super 1.2 [2/1] [U_]
+//	      [==>..................]  recovery = 12.6% (246291456/1953382464) finish=141.3min speed=201330K/sec
+func readMDStat() map[string]mdstatArray {
+	arrays := map[string]mdstatArray{}
+
+	file, err := os.Open(prefix + "/proc/mdstat")
+	if err != nil {
+		return arrays
+	}
+	defer file.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if submatches := mdstatArrayRegex.FindStringSubmatch(line); submatches != nil {
+			current = submatches[1]
+			arrays[current] = mdstatArray{}
+			continue
+		}
+		if current == "" || strings.TrimSpace(line) == "" {
+			current = ""
+			continue
+		}
+		array := arrays[current]
+		if status := mdstatStatusRegex.FindString(line); status != "" {
+			array.status = status
+		}
+		if progress := mdstatProgressRegex.FindString(line); progress != "" {
+			array.progress = progress
+		}
+		arrays[current] = array
+	}
+	return arrays
+}
@@ -0,0 +1,128 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetStorageStacks(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	// an LVM volume on a LUKS volume on a degraded RAID1 of two partitions
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/block/sda/dev":               "8:0\n",
+		"sys/block/sda/size":              "2000\n",
+		"sys/block/sda/sda1/partition":    "1\n",
+		"sys/block/sda/sda1/dev":          "8:1\n",
+		"sys/block/sda/sda1/size":         "1000\n",
+		"sys/block/sdb/dev":               "8:16\n",
+		"sys/block/sdb/size":              "2000\n",
+		"sys/block/sdb/sdb1/partition":    "1\n",
+		"sys/block/sdb/sdb1/dev":          "8:17\n",
+		"sys/block/sdb/sdb1/size":         "1000\n",
+		"sys/block/md0/dev":               "9:0\n",
+		"sys/block/md0/size":              "990\n",
+		"sys/block/md0/slaves/sda1":       "",
+		"sys/block/md0/slaves/sdb1":       "",
+		"sys/block/md0/md/level":          "raid1\n",
+		"sys/block/md0/md/array_state":    "clean\n",
+		"sys/block/md0/md/raid_disks":     "2\n",
+		"sys/block/md0/md/degraded":       "1\n",
+		"sys/block/md0/md/sync_action":    "recover\n",
+		"sys/block/md0/md/dev-sda1/slot":  "0\n",
+		"sys/block/md0/md/dev-sda1/state": "in_sync\n",
+		"sys/block/md0/md/dev-sdb1/slot":  "none\n",
+		"sys/block/md0/md/dev-sdb1/state": "spare\n",
+		"sys/block/dm-0/dev":              "253:0\n",
+		"sys/block/dm-0/size":             "980\n",
+		"sys/block/dm-0/slaves/md0":       "",
+		"sys/block/dm-0/dm/name":          "luks-root\n",
+		"sys/block/dm-0/dm/uuid":          "CRYPT-LUKS2-0123456789abcdef0123456789abcdef-luks-root\n",
+		"sys/block/dm-0/dm/suspended":     "0\n",
+		"sys/block/dm-1/dev":              "253:1\n",
+		"sys/block/dm-1/size":             "900\n",
+		"sys/block/dm-1/slaves/dm-0":      "",
+		"sys/block/dm-1/dm/name":          "vg--sys-lv--root\n",
+		"sys/block/dm-1/dm/uuid":          "LVM-abcdefABCDEF0123456789abcdefABCDEF0123456789abcdefABCDEF01\n",
+		"sys/block/dm-1/dm/suspended":     "0\n",
+		"proc/mdstat": `Personalities : [raid1]
+md0 : active raid1 sdb1[1] sda1[0]
+      495 blocks super 1.2 [2/1] [U_]
+      [==>..................]  recovery = 12.6% (62/495) finish=1.3min speed=201330K/sec
+
+unused devices: <none>
+`,
+	})
+
+	stacks, err := GetStorageStacks()
+	require.NoError(t, err)
+	require.Len(t, stacks, 7)
+
+	sda := StackNode{Name: "sda", Device: "8:0", Type: "disk", SizeBytes: 2000 * 512, Lower: []StackNode{}}
+	sdb := StackNode{Name: "sdb", Device: "8:16", Type: "disk", SizeBytes: 2000 * 512, Lower: []StackNode{}}
+	md0 := StackNode{
+		Name:      "md0",
+		Device:    "9:0",
+		Type:      "raid1",
+		SizeBytes: 990 * 512,
+		MD: &RAID{
+			Level:      "raid1",
+			State:      "clean",
+			RaidDisks:  2,
+			Degraded:   1,
+			SyncAction: "recover",
+			Status:     "[2/1] [U_]",
+			Progress:   "recovery = 12.6%",
+			Members: []RAIDMember{
+				{Name: "sda1", Slot: "0", State: "in_sync"},
+				{Name: "sdb1", Slot: "none", State: "spare"},
+			},
+		},
+		Lower: []StackNode{
+			{Name: "sda1", Device: "8:1", Type: "partition", SizeBytes: 1000 * 512, Lower: []StackNode{sda}},
+			{Name: "sdb1", Device: "8:17", Type: "partition", SizeBytes: 1000 * 512, Lower: []StackNode{sdb}},
+		},
+	}
+	require.Equal(t, &StackNode{
+		Name:      "dm-1",
+		Device:    "253:1",
+		Type:      "lvm",
+		SizeBytes: 900 * 512,
+		DM: &DeviceMapper{
+			Name:          "vg--sys-lv--root",
+			UUID:          "LVM-abcdefABCDEF0123456789abcdefABCDEF0123456789abcdefABCDEF01",
+			VolumeGroup:   "vg-sys",
+			LogicalVolume: "lv-root",
+		},
+		Lower: []StackNode{{
+			Name:      "dm-0",
+			Device:    "253:0",
+			Type:      "crypt",
+			SizeBytes: 980 * 512,
+			DM: &DeviceMapper{
+				Name: "luks-root",
+				UUID: "CRYPT-LUKS2-0123456789abcdef0123456789abcdef-luks-root",
+			},
+			Lower: []StackNode{md0},
+		}},
+	}, stacks["253:1"])
+}
+
+func TestSplitLVMName(t *testing.T) {
+	for name, expected := range map[string][2]string{
+		"vg-root":               {"vg", "root"},
+		"vg--data-lv--web":      {"vg-data", "lv-web"},
+		"ubuntu--vg-ubuntu--lv": {"ubuntu-vg", "ubuntu-lv"},
+		"novg":                  {"", "novg"},
+	} {
+		vg, lv := splitLVMName(name)
+		require.Equal(t, expected, [2]string{vg, lv}, name)
+	}
+}
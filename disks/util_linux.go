@@ -0,0 +1,81 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package disks
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var prefix = "" // only used for testing
+
+// readString reads a whitespace-trimmed string from the file at the given
+// absolute path
+func readString(path string) (string, bool) {
+	content, err := ioutil.ReadFile(prefix + path)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(content)), true
+}
+
+// readUint reads an unsigned integer from the file at the given absolute path
+func readUint(path string) (uint64, bool) {
+	content, ok := readString(path)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// readBool reads a 0/1 flag from the file at the given absolute path
+func readBool(path string) bool {
+	value, ok := readUint(path)
+	return ok && value != 0
+}
+
+// exists tells whether the file at the given absolute path exists
+func exists(path string) bool {
+	_, err := os.Stat(prefix + path)
+	return err == nil
+}
+
+// listDir returns the sorted names of the entries of the directory at the
+// given absolute path, or an empty list if it cannot be read
+func listDir(path string) []string {
+	names := []string{}
+	dirents, err := os.ReadDir(prefix + path)
+	if err != nil {
+		return names
+	}
+	for _, dirent := range dirents {
+		names = append(names, dirent.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectedMode returns the selected value of a sysfs file listing the
+// possible values with the selected one in brackets, eg. `none
+// [mq-deadline] kyber`
+func selectedMode(content string) string {
+	for _, mode := range strings.Fields(content) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]")
+		}
+	}
+	return content
+}
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -22,21 +23,85 @@ var dfCommand = "df"
 var dfOptions = []string{"-l", "-k"}
 var dfTimeout = 2 * time.Second
 
-func getFileSystemInfo() (interface{}, error) {
+// dfMount is a filesystem as reported by `df`, its size being kept as the
+// raw `1K-blocks` string
+type dfMount struct {
+	name      string
+	kbSize    string
+	mountedOn string
+}
+
+// getDfFileSystemInfo returns the legacy entries of the filesystems
+// collected by `df`: their `name`, `kb_size` and `mounted_on` strings
+func getDfFileSystemInfo() (interface{}, error) {
+	mounts, err := runDf()
+	if err != nil {
+		return nil, err
+	}
+
+	fileSystemInfo := make([]interface{}, 0, len(mounts))
+	for _, mount := range mounts {
+		fileSystemInfo = append(fileSystemInfo, map[string]string{
+			"name":       mount.name,
+			"kb_size":    mount.kbSize,
+			"mounted_on": mount.mountedOn,
+		})
+	}
+	return fileSystemInfo, nil
+}
+
+// getDfMounts collects the local filesystems by running `df`.  It is used on
+// darwin, and on Linux when /proc/self/mountinfo cannot be read.  A size
+// which cannot be parsed, eg. "-" for an automounter map, is reported as zero
+// with a warning.
+func getDfMounts() ([]MountInfo, []string, error) {
+	mounts, err := runDf()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]MountInfo, 0, len(mounts))
+	var warnings []string
+	for _, mount := range mounts {
+		info := MountInfo{Name: mount.name, MountedOn: mount.mountedOn}
+		if kb, err := strconv.ParseUint(mount.kbSize, 10, 64); err == nil {
+			info.SizeBytes = kb * 1024
+		} else {
+			warnings = append(warnings, fmt.Sprintf("could not parse the size %q of %s", mount.kbSize, mount.mountedOn))
+		}
+		result = append(result, info)
+	}
+	return result, warnings, nil
+}
+
+// runDf runs `df` and returns the filesystems passing the filter rules
+func runDf() ([]dfMount, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dfTimeout)
 	defer cancel()
 
+	// `-l` restricts df to the local filesystems
+	args := dfOptions
+	if options.remote {
+		args = []string{}
+		for _, option := range dfOptions {
+			if option != "-l" {
+				args = append(args, option)
+			}
+		}
+	}
+
 	/* Grab filesystem data from df	*/
-	cmd := exec.CommandContext(ctx, dfCommand, dfOptions...)
+	cmd := exec.CommandContext(ctx, dfCommand, args...)
 
 	// force output in the C locale (untranslated) so that we can recognize the headers
 	cmd.Env = []string{"LC_ALL=C"}
 
 	out, execErr := cmd.Output()
 	var parseErr error
-	var result []interface{}
+	var result []dfMount
 	if out != nil {
 		result, parseErr = parseDfOutput(string(out))
+		result = filterDfOutput(result, &options)
 	}
 
 	// if we managed to get _any_ data, just use it, ignoring other errors
@@ -55,12 +120,24 @@ func getFileSystemInfo() (interface{}, error) {
 	return nil, fmt.Errorf("df failed to collect filesystem data: %s", err)
 }
 
-func parseDfOutput(out string) ([]interface{}, error) {
+// filterDfOutput keeps the filesystems passing the path and device rules,
+// df not reporting the filesystem types
+func filterDfOutput(mounts []dfMount, rules *filterRules) []dfMount {
+	filtered := make([]dfMount, 0, len(mounts))
+	for _, mount := range mounts {
+		if rules.keep("", mount.mountedOn, mount.name) {
+			filtered = append(filtered, mount)
+		}
+	}
+	return filtered
+}
+
+func parseDfOutput(out string) ([]dfMount, error) {
 	lines := strings.Split(out, "\n")
 	if len(lines) < 2 {
 		return nil, errors.New("no output")
 	}
-	var fileSystemInfo = make([]interface{}, 0, len(lines)-2)
+	var mounts = make([]dfMount, 0, len(lines)-2)
 
 	// parse the header to find the offsets for each component we need
 	hdr := lines[0]
@@ -87,21 +164,21 @@ func parseDfOutput(out string) ([]interface{}, error) {
 		if len(line) == 0 || len(line) < mountedOnOffset {
 			continue
 		}
-		info := map[string]string{}
+		var mount dfMount
 
 		// we assume that "Filesystem" is the leftmost field, and continues to the
 		// beginning of "1K-blocks".
-		info["name"] = strings.Trim(line[:kbsizeOffset], " ")
+		mount.name = strings.Trim(line[:kbsizeOffset], " ")
 
 		// kbsize is right-aligned under "1K-blocks", so strip leading
 		// whitespace and the discard everything after the next whitespace
 		kbsizeAndMore := strings.TrimLeft(line[kbsizeOffset:], " ")
-		info["kb_size"] = strings.SplitN(kbsizeAndMore, " ", 2)[0]
+		mount.kbSize = strings.SplitN(kbsizeAndMore, " ", 2)[0]
 
 		// mounted_on is left-aligned under "Mounted on" and continues to EOL
-		info["mounted_on"] = strings.Trim(line[mountedOnOffset:], " ")
+		mount.mountedOn = strings.Trim(line[mountedOnOffset:], " ")
 
-		fileSystemInfo = append(fileSystemInfo, info)
+		mounts = append(mounts, mount)
 	}
-	return fileSystemInfo, nil
+	return mounts, nil
 }
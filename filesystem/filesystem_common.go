@@ -6,9 +6,75 @@
 // Package filesystem regroups collecting information about the filesystem
 package filesystem
 
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/gohai/disks"
+)
+
 // FileSystem is the Collector type of the filesystem package.
 type FileSystem struct{}
 
+// MountInfo describes a mounted filesystem.  Only Name, MountedOn and the
+// sizes are known on all platforms: the other fields are collected on Linux,
+// from /proc/self/mountinfo and statfs, unless it falls back to `df`.
+type MountInfo struct {
+	// Name is the source of the mount, eg. "/dev/sda1", or the volume name on
+	// Windows
+	Name string `json:"name"`
+	// MountedOn is where the filesystem is mounted
+	MountedOn string `json:"mounted_on"`
+	// SizeBytes is the size of the filesystem
+	SizeBytes uint64 `json:"size_bytes"`
+	// UsedBytes is the space in use (Linux and Windows only)
+	UsedBytes uint64 `json:"used_bytes"`
+	// AvailableBytes is the space available to unprivileged users on Linux,
+	// and the free space on Windows
+	AvailableBytes uint64 `json:"available_bytes"`
+
+	// FSType is the filesystem type, eg. "ext4"
+	FSType string `json:"fs_type"`
+	// MountOptions are the per-mount options, eg. "rw" or "noexec"
+	MountOptions []string `json:"mount_options"`
+	// SuperOptions are the per-superblock options
+	SuperOptions []string `json:"super_options"`
+	// ReadOnly tells whether the mount or its superblock is read-only
+	ReadOnly bool `json:"read_only"`
+	// MountID is the unique ID of the mount
+	MountID uint64 `json:"mount_id"`
+	// ParentID is the ID of the parent mount
+	ParentID uint64 `json:"parent_id"`
+	// Device is the major:minor number of the device holding the filesystem
+	Device string `json:"device"`
+	// Root is the directory of the filesystem mounted, eg. for bind mounts
+	Root string `json:"root"`
+	// InodesTotal is the number of inodes of the filesystem
+	InodesTotal uint64 `json:"inodes_total"`
+	// InodesFree is the number of free inodes
+	InodesFree uint64 `json:"inodes_free"`
+	// ReservedRatio is the share of the blocks reserved for root
+	ReservedRatio float64 `json:"reserved_ratio"`
+
+	// StorageStack is the stack of block devices under the filesystem
+	StorageStack *disks.StackNode `json:"storage_stack,omitempty"`
+	// Classification is "local", "network", "pseudo", "overlay" or
+	// "removable"
+	Classification string `json:"classification"`
+	// Pool is the btrfs or ZFS pool shared by the filesystem
+	Pool *Pool `json:"pool,omitempty"`
+	// Quotas holds the quotas of the filesystem, see the `filesystem-quotas`
+	// flag
+	Quotas *Quotas `json:"quotas,omitempty"`
+
+	// Health is "ok", "slow", "stale" or "ro-error".  Stale mounts have no
+	// sizes.
+	Health string `json:"health"`
+	// HealthLatencyMilliseconds is the duration of the health probe
+	HealthLatencyMilliseconds float64 `json:"health_latency_ms"`
+	// HealthError is the error of the health probe
+	HealthError string `json:"health_error,omitempty"`
+}
+
 const name = "filesystem"
 
 // Name returns the name of the package
@@ -23,3 +89,40 @@ func (filesystem *FileSystem) Collect() (result interface{}, err error) {
 	result, err = getFileSystemInfo()
 	return
 }
+
+// Details collects the mounted filesystems with all their details, which do
+// not fit in the flat legacy payload of FileSystem, under their own
+// "filesystem_details" key
+type Details struct{}
+
+// Name returns the name of the collector
+func (details *Details) Name() string {
+	return name + "_details"
+}
+
+// Collect collects the mounted filesystems with all their details.
+// Returns an object which can be converted to a JSON or an error if nothing could be collected.
+// Tries to collect as much information as possible.
+func (details *Details) Collect() (result interface{}, err error) {
+	mounts, warnings, err := getMounts()
+	logWarnings(warnings)
+	if err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// Get returns the mounted filesystems, a list of warnings and an error. The method will try to collect as much
+// metadata as possible, an error is returned if nothing could be collected. The list of warnings contains errors if
+// some metadata could not be collected.
+func Get() ([]MountInfo, []string, error) {
+	return getMounts()
+}
+
+// logWarnings logs the warnings of getMounts, the legacy payload having no
+// room for them
+func logWarnings(warnings []string) {
+	for _, warning := range warnings {
+		log.Warnf("[%s] %s", name, warning)
+	}
+}
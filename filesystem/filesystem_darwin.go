@@ -0,0 +1,14 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+func getFileSystemInfo() (interface{}, error) {
+	return getDfFileSystemInfo()
+}
+
+func getMounts() ([]MountInfo, []string, error) {
+	return getDfMounts()
+}
@@ -0,0 +1,385 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/disks"
+	"github.com/DataDog/gohai/utils"
+)
+
+var mountInfoPath = "/proc/self/mountinfo"
+var statfs = unix.Statfs
+var statfsTimeout = 2 * time.Second
+var storageStacks = disks.GetStorageStacks
+
+// dummyFileSystems are the pseudo filesystems which hold no data, skipped
+// without calling statfs on them (which would trigger autofs mounts)
+var dummyFileSystems = map[string]bool{
+	"autofs":      true,
+	"binfmt_misc": true,
+	"bpf":         true,
+	"cgroup":      true,
+	"cgroup2":     true,
+	"configfs":    true,
+	"debugfs":     true,
+	"devpts":      true,
+	"fusectl":     true,
+	"mqueue":      true,
+	"nsfs":        true,
+	"proc":        true,
+	"pstore":      true,
+	"rpc_pipefs":  true,
+	"securityfs":  true,
+	"selinuxfs":   true,
+	"sysfs":       true,
+	"tracefs":     true,
+}
+
+// remoteFileSystems are the network filesystems, skipped as `df -l` does
+var remoteFileSystems = map[string]bool{
+	"afs":            true,
+	"ceph":           true,
+	"cifs":           true,
+	"coda":           true,
+	"fuse.gcsfuse":   true,
+	"fuse.glusterfs": true,
+	"fuse.goofys":    true,
+	"fuse.rclone":    true,
+	"fuse.s3fs":      true,
+	"fuse.sshfs":     true,
+	"glusterfs":      true,
+	"gpfs":           true,
+	"lustre":         true,
+	"ncpfs":          true,
+	"nfs":            true,
+	"nfs4":           true,
+	"smb3":           true,
+	"smbfs":          true,
+}
+
+// virtualFileSystems are the filesystems held in memory or in images rather
+// than on a disk of their own, classified as pseudo filesystems
+var virtualFileSystems = map[string]bool{
+	"devtmpfs":   true,
+	"efivarfs":   true,
+	"fuse.lxcfs": true,
+	"hugetlbfs":  true,
+	"ramfs":      true,
+	"squashfs":   true,
+	"tmpfs":      true,
+}
+
+// overlayFileSystems are the union filesystems used by container runtimes
+var overlayFileSystems = map[string]bool{
+	"aufs":                true,
+	"fuse.fuse-overlayfs": true,
+	"overlay":             true,
+}
+
+// classifications of the filesystems
+const (
+	classLocal     = "local"
+	classNetwork   = "network"
+	classPseudo    = "pseudo"
+	classOverlay   = "overlay"
+	classRemovable = "removable"
+)
+
+// getFileSystemInfo returns the legacy entries of the filesystems: their
+// `name` (the mount source), `kb_size` and `mounted_on` strings, falling back
+// to `df` if mountinfo cannot be read
+func getFileSystemInfo() (interface{}, error) {
+	mountInfo, err := utils.ReadMountInfo(mountInfoPath)
+	if err != nil {
+		return getDfFileSystemInfo()
+	}
+
+	mounts, warnings, err := getNativeMounts(mountInfo)
+	logWarnings(warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	fileSystemInfo := make([]interface{}, 0, len(mounts))
+	for _, mount := range mounts {
+		fileSystemInfo = append(fileSystemInfo, map[string]string{
+			"name":       mount.Name,
+			"kb_size":    strconv.FormatUint(mount.SizeBytes/1024, 10),
+			"mounted_on": mount.MountedOn,
+		})
+	}
+	return fileSystemInfo, nil
+}
+
+// getMounts collects the filesystems from /proc/self/mountinfo and statfs,
+// falling back to `df` if mountinfo cannot be read
+func getMounts() ([]MountInfo, []string, error) {
+	mountInfo, err := utils.ReadMountInfo(mountInfoPath)
+	if err != nil {
+		return getDfMounts()
+	}
+	return getNativeMounts(mountInfo)
+}
+
+// getNativeMounts collects the filesystems of mountInfo selected by options.
+// The mounts are probed in parallel, each step of a probe being bounded by
+// statfsTimeout, so that a hung mount is only reported as stale.
+func getNativeMounts(mountInfo []utils.MountInfo) ([]MountInfo, []string, error) {
+	selected := selectMounts(mountInfo, &options)
+	probes := make([]probe, len(selected))
+	var wg sync.WaitGroup
+	for i := range selected {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			probes[i] = probeMount(selected[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// the stacks are only missing when /sys/block cannot be read
+	stacks, _ := storageStacks()
+	btrfs := readBtrfsFileSystems()
+
+	mounts := make([]MountInfo, 0, len(selected))
+	warnings := []string{}
+	var lastErr error
+	for i, mount := range selected {
+		p := probes[i]
+		if p.statErr != nil {
+			warnings = append(warnings, fmt.Sprintf("could not stat %s: %s", mount.MountPoint, p.statErr))
+			lastErr = p.statErr
+			if p.health != healthStale {
+				continue
+			}
+		} else if p.stat.Blocks == 0 && !options.includeTypes.matches(mount.FSType) {
+			continue
+		}
+
+		m := newMountInfo(mount, p)
+		m.StorageStack = findStorageStack(stacks, mount)
+		m.Classification = classify(mount, m.StorageStack)
+		m.Pool = findPool(mount, btrfs)
+		if quotaOptions.enabled {
+			// the quotas may be partial, eg. without the usage of the
+			// other IDs for non-root users
+			quotas, err := getQuotas(mount)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("could not read the quotas of %s: %s", mount.MountPoint, err))
+			}
+			m.Quotas = quotas
+		}
+		mounts = append(mounts, m)
+	}
+
+	if len(mounts) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no filesystem found")
+		}
+		return nil, warnings, fmt.Errorf("could not collect filesystem data: %s", lastErr)
+	}
+	return mounts, warnings, nil
+}
+
+// newMountInfo returns the mountinfo fields of a mount, with the usage
+// reported by statfs and the result of its health probe.  Stale mounts have
+// no statfs result, and thus no usage.
+func newMountInfo(mount utils.MountInfo, p probe) MountInfo {
+	m := MountInfo{
+		Name:                      mount.Source,
+		MountedOn:                 mount.MountPoint,
+		FSType:                    mount.FSType,
+		MountOptions:              mount.MountOptions,
+		SuperOptions:              mount.SuperOptions,
+		ReadOnly:                  hasOption(mount.MountOptions, "ro") || hasOption(mount.SuperOptions, "ro"),
+		MountID:                   mount.MountID,
+		ParentID:                  mount.ParentID,
+		Device:                    fmt.Sprintf("%d:%d", mount.Major, mount.Minor),
+		Root:                      mount.Root,
+		Health:                    p.health,
+		HealthLatencyMilliseconds: float64(p.latency) / float64(time.Millisecond),
+	}
+	if p.statErr != nil {
+		m.HealthError = p.statErr.Error()
+	} else if p.writeErr != nil {
+		m.HealthError = p.writeErr.Error()
+	}
+
+	stat := p.stat
+	if stat == nil {
+		return m
+	}
+	bsize := uint64(blockSize(stat))
+	m.SizeBytes = stat.Blocks * bsize
+	m.UsedBytes = (stat.Blocks - stat.Bfree) * bsize
+	m.AvailableBytes = stat.Bavail * bsize
+	m.InodesTotal = stat.Files
+	m.InodesFree = stat.Ffree
+
+	// the blocks reserved for root are free but not available to users
+	if stat.Blocks != 0 && stat.Bfree > stat.Bavail {
+		m.ReservedRatio = float64(stat.Bfree-stat.Bavail) / float64(stat.Blocks)
+	}
+	return m
+}
+
+// findStorageStack returns the storage stack under the device of the mount.
+// Filesystems spanning several devices (eg. btrfs) report an anonymous
+// device in mountinfo, in which case the device of the mount source is used.
+func findStorageStack(stacks map[string]*disks.StackNode, mount utils.MountInfo) *disks.StackNode {
+	if stack, ok := stacks[fmt.Sprintf("%d:%d", mount.Major, mount.Minor)]; ok {
+		return stack
+	}
+
+	if !strings.HasPrefix(mount.Source, "/dev/") {
+		return nil
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(mount.Source, &stat); err != nil || stat.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return nil
+	}
+	rdev := uint64(stat.Rdev)
+	return stacks[fmt.Sprintf("%d:%d", unix.Major(rdev), unix.Minor(rdev))]
+}
+
+// hasOption tells whether the mount options contain option
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// selectMounts keeps the mounts passing the rules, and a single mount of each
+// device (the one with the shortest mount point), in the order of mountinfo.
+// All the subvolumes of a btrfs filesystem share its device, so they are told
+// apart by their subvolume ID, only their bind mounts being skipped.  Mounts
+// shadowed by a later mount on the same mount point are skipped, as
+// are the pseudo filesystems holding no data and the network filesystems
+// unless their type is explicitly included.
+func selectMounts(mounts []utils.MountInfo, rules *filterRules) []utils.MountInfo {
+	lastMount := map[string]int{}
+	for i, mount := range mounts {
+		lastMount[mount.MountPoint] = i
+	}
+
+	type device struct {
+		major, minor uint64
+		subvolume    string
+	}
+	kept := map[device]int{}
+	var selected []utils.MountInfo
+	for i, mount := range mounts {
+		if lastMount[mount.MountPoint] != i || !rules.keep(mount.FSType, mount.MountPoint, mount.Source) {
+			continue
+		}
+		if !rules.includeTypes.matches(mount.FSType) {
+			if dummyFileSystems[mount.FSType] || (isRemote(mount) && !rules.remote) {
+				continue
+			}
+		}
+		dev := device{major: mount.Major, minor: mount.Minor}
+		if mount.FSType == "btrfs" {
+			dev.subvolume = superOption(mount.SuperOptions, "subvolid")
+		}
+		if j, ok := kept[dev]; ok {
+			if len(mount.MountPoint) < len(selected[j].MountPoint) {
+				selected[j] = mount
+			}
+			continue
+		}
+		kept[dev] = len(selected)
+		selected = append(selected, mount)
+	}
+	return selected
+}
+
+// classify returns the classification of a mount: "overlay" for container
+// union filesystems, "network", "pseudo" for filesystems without a disk of
+// their own, "removable" for removable and USB disks, or "local"
+func classify(mount utils.MountInfo, stack *disks.StackNode) string {
+	switch {
+	case overlayFileSystems[mount.FSType]:
+		return classOverlay
+	case isRemote(mount):
+		return classNetwork
+	case dummyFileSystems[mount.FSType] || virtualFileSystems[mount.FSType]:
+		return classPseudo
+	case stack != nil && hasRemovableDisk(stack):
+		return classRemovable
+	}
+	return classLocal
+}
+
+// hasRemovableDisk tells whether any disk of the storage stack is removable
+func hasRemovableDisk(stack *disks.StackNode) bool {
+	if stack.Removable {
+		return true
+	}
+	for i := range stack.Lower {
+		if hasRemovableDisk(&stack.Lower[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRemote tells whether the mount is a network filesystem, either by its
+// type or by a `host:/path` or `//host/share` source
+func isRemote(mount utils.MountInfo) bool {
+	if remoteFileSystems[mount.FSType] {
+		return true
+	}
+	return (strings.Contains(mount.Source, ":/") && !strings.HasPrefix(mount.Source, "/")) ||
+		strings.HasPrefix(mount.Source, "//")
+}
+
+// statfsWithTimeout calls statfs on path, giving up after statfsTimeout.  A
+// statfs call that never returns leaks its goroutine, which cannot be helped
+// as the call is stuck in the kernel.
+func statfsWithTimeout(path string) (*unix.Statfs_t, error) {
+	type result struct {
+		stat unix.Statfs_t
+		err  error
+	}
+	done := make(chan result, 1)
+	statfsFunc := statfs
+	go func() {
+		var r result
+		r.err = statfsFunc(path, &r.stat)
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &r.stat, nil
+	case <-time.After(statfsTimeout):
+		return nil, fmt.Errorf("statfs %w after %s", errTimedOut, statfsTimeout)
+	}
+}
+
+// blockSize returns the fundamental block size of the filesystem, in which
+// the block counts are expressed
+func blockSize(stat *unix.Statfs_t) int64 {
+	if stat.Frsize != 0 {
+		return int64(stat.Frsize)
+	}
+	return int64(stat.Bsize)
+}
@@ -0,0 +1,247 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/disks"
+	"github.com/DataDog/gohai/utils"
+)
+
+const testMountInfo = `22 1 8:1 / / ro,relatime shared:1 - ext4 /dev/sda1 ro,errors=remount-ro
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:22 / /sys rw,nosuid,nodev,noexec,relatime shared:2 - sysfs sysfs rw
+25 22 0:5 / /dev rw,nosuid shared:8 - devtmpfs udev rw,size=8155120k,mode=755
+26 25 0:23 / /dev/shm rw,nosuid,nodev shared:9 - tmpfs tmpfs rw
+33 26 0:49 / /dev/shm rw,nosuid,nodev shared:9 - tmpfs shm rw
+27 22 8:2 / /home/my\040data rw,relatime shared:30 - xfs /dev/sda2 rw
+28 22 8:1 /var/lib/docker /var/lib/docker rw,relatime shared:1 - ext4 /dev/sda1 rw
+29 22 0:45 / /mnt/nfs rw,relatime shared:40 - nfs4 server:/export rw
+30 22 0:46 / /mnt/stuck rw,relatime shared:41 - fuse.rclone remote: rw
+31 22 0:47 / /mnt/autofs rw,relatime shared:42 - autofs systemd-1 rw
+32 22 0:48 / /run/empty rw,relatime shared:43 - ramfs none rw
+`
+
+var testStack = &disks.StackNode{
+	Name:   "sda1",
+	Device: "8:1",
+	Type:   "partition",
+	Lower:  []disks.StackNode{{Name: "sda", Device: "8:0", Type: "disk", Lower: []disks.StackNode{}}},
+}
+
+func withStatfs(t *testing.T, mountInfo string, stats map[string]unix.Statfs_t, hung map[string]bool) {
+	path := filepath.Join(t.TempDir(), "mountinfo")
+	require.NoError(t, ioutil.WriteFile(path, []byte(mountInfo), 0o666))
+
+	oldPath, oldStatfs, oldTimeout, oldStacks := mountInfoPath, statfs, statfsTimeout, storageStacks
+	oldBtrfs, oldZFS := btrfsPath, zfsKstatPath
+	release := make(chan struct{})
+	mountInfoPath = path
+	btrfsPath = filepath.Join(t.TempDir(), "btrfs")
+	zfsKstatPath = filepath.Join(t.TempDir(), "zfs")
+	storageStacks = func() (map[string]*disks.StackNode, error) {
+		return map[string]*disks.StackNode{"8:1": testStack}, nil
+	}
+	statfsTimeout = 20 * time.Millisecond // test faster
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		if hung[path] {
+			<-release
+			return unix.EIO
+		}
+		stat, ok := stats[path]
+		if !ok {
+			t.Errorf("unexpected statfs of %s", path)
+			return unix.ENOENT
+		}
+		*buf = stat
+		return nil
+	}
+	t.Cleanup(func() {
+		close(release)
+		mountInfoPath, statfs, statfsTimeout, storageStacks = oldPath, oldStatfs, oldTimeout, oldStacks
+		btrfsPath, zfsKstatPath = oldBtrfs, oldZFS
+	})
+}
+
+func withRemote(t *testing.T) {
+	options.remote = true
+	t.Cleanup(func() { options.remote = false })
+}
+
+func TestNativeFileSystemInfo(t *testing.T) {
+	withRemote(t)
+	withStatfs(t, testMountInfo, map[string]unix.Statfs_t{
+		"/":             {Bsize: 4096, Frsize: 4096, Blocks: 4049370, Bfree: 800000, Bavail: 597532, Files: 1015808, Ffree: 3},
+		"/dev":          {Bsize: 4096, Blocks: 2038780},
+		"/dev/shm":      {Bsize: 4096, Blocks: 2041280},
+		"/home/my data": {Bsize: 4096, Frsize: 1024, Blocks: 1048576},
+		"/mnt/nfs":      {Bsize: 65536, Blocks: 16384},
+		"/run/empty":    {Bsize: 4096},
+	}, map[string]bool{"/mnt/stuck": true})
+
+	out, err := getFileSystemInfo()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[string]string{"kb_size": "16197480", "mounted_on": "/", "name": "/dev/sda1"},
+		map[string]string{"kb_size": "8155120", "mounted_on": "/dev", "name": "udev"},
+		map[string]string{"kb_size": "8165120", "mounted_on": "/dev/shm", "name": "shm"},
+		map[string]string{"kb_size": "1048576", "mounted_on": "/home/my data", "name": "/dev/sda2"},
+		map[string]string{"kb_size": "1048576", "mounted_on": "/mnt/nfs", "name": "server:/export"},
+		map[string]string{"kb_size": "0", "mounted_on": "/mnt/stuck", "name": "remote:"},
+	}, out)
+}
+
+func TestSelectMounts(t *testing.T) {
+	mounts := []utils.MountInfo{
+		{MountPoint: "/", FSType: "ext4", Source: "/dev/sda1", Major: 8, Minor: 1},
+		{MountPoint: "/proc", FSType: "proc", Source: "proc", Minor: 21},
+		{MountPoint: "/run", FSType: "tmpfs", Source: "tmpfs", Minor: 24},
+		{MountPoint: "/snap/core/1", FSType: "squashfs", Source: "/dev/loop0", Major: 7},
+		{MountPoint: "/mnt/nfs", FSType: "nfs4", Source: "server:/export", Minor: 45},
+		{MountPoint: "/mnt/s3", FSType: "fuse.s3fs", Source: "s3fs", Minor: 46},
+		{MountPoint: "/var/lib/docker/overlay2/abc/merged", FSType: "overlay", Source: "overlay", Minor: 50},
+	}
+	mountPoints := func(rules filterRules) []string {
+		points := []string{}
+		for _, mount := range selectMounts(mounts, &rules) {
+			points = append(points, mount.MountPoint)
+		}
+		return points
+	}
+
+	require.Equal(t, []string{"/", "/run", "/snap/core/1", "/var/lib/docker/overlay2/abc/merged"}, mountPoints(filterRules{}))
+	require.Equal(t, []string{"/", "/run", "/snap/core/1", "/mnt/nfs", "/mnt/s3", "/var/lib/docker/overlay2/abc/merged"}, mountPoints(filterRules{remote: true}))
+	require.Equal(t, []string{"/proc", "/mnt/nfs"}, mountPoints(filterRules{includeTypes: patternList{"proc", "nfs*"}}))
+	require.Equal(t, []string{"/", "/run"}, mountPoints(filterRules{
+		excludeTypes:   patternList{"overlay"},
+		excludeDevices: patternList{"/dev/loop*"},
+	}))
+	require.Equal(t, []string{"/", "/snap/core/1"}, mountPoints(filterRules{includeDevices: patternList{"/dev/*"}}))
+	require.Equal(t, []string{"/", "/run"}, mountPoints(filterRules{excludePaths: patternList{"/snap/*/*", "/var/lib/docker/*/*/merged"}}))
+}
+
+func TestSelectMountsBtrfsSubvolumes(t *testing.T) {
+	mountInfo, err := utils.ParseMountInfo(strings.NewReader(`22 1 0:31 /@ / rw,relatime shared:1 - btrfs /dev/sda2 rw,ssd,space_cache=v2,subvolid=256,subvol=/@
+23 22 0:31 /@home /home rw,relatime shared:2 - btrfs /dev/sda2 rw,ssd,space_cache=v2,subvolid=257,subvol=/@home
+24 22 0:31 /@home /srv/home rw,relatime shared:2 - btrfs /dev/sda2 rw,ssd,space_cache=v2,subvolid=257,subvol=/@home
+25 22 8:1 / /boot rw,relatime shared:3 - ext4 /dev/sda1 rw
+26 22 8:1 / /mnt/boot rw,relatime shared:3 - ext4 /dev/sda1 rw
+`))
+	require.NoError(t, err)
+
+	points := []string{}
+	for _, mount := range selectMounts(mountInfo, &filterRules{}) {
+		points = append(points, mount.MountPoint)
+	}
+	// both subvolumes are kept, but not their bind mounts
+	require.Equal(t, []string{"/", "/home", "/boot"}, points)
+}
+
+func TestClassify(t *testing.T) {
+	usb := &disks.StackNode{Name: "sdb1", Type: "partition", Lower: []disks.StackNode{{Name: "sdb", Type: "disk", Removable: true}}}
+	for expected, mount := range map[string]utils.MountInfo{
+		"local":     {FSType: "xfs", Source: "/dev/mapper/vg-root"},
+		"network":   {FSType: "fuse.rclone", Source: "remote:"},
+		"pseudo":    {FSType: "squashfs", Source: "/dev/loop3"},
+		"overlay":   {FSType: "overlay", Source: "overlay"},
+		"removable": {FSType: "vfat", Source: "/dev/sdb1"},
+	} {
+		var stack *disks.StackNode
+		if expected == "removable" {
+			stack = usb
+		}
+		require.Equal(t, expected, classify(mount, stack), mount.FSType)
+	}
+}
+
+func TestNativeFileSystemInfoAllStale(t *testing.T) {
+	withStatfs(t, testMountInfo, nil, map[string]bool{
+		"/": true, "/dev": true, "/dev/shm": true, "/home/my data": true, "/run/empty": true,
+	})
+
+	mounts, warnings, err := Get()
+	require.NoError(t, err)
+	require.Len(t, warnings, 5)
+	require.Len(t, mounts, 5)
+	for _, mount := range mounts {
+		require.Equal(t, "stale", mount.Health)
+	}
+}
+
+func TestNativeFileSystemInfoAllFailed(t *testing.T) {
+	withStatfs(t, testMountInfo, nil, nil)
+	statfs = func(path string, buf *unix.Statfs_t) error {
+		return unix.EACCES
+	}
+
+	_, err := getFileSystemInfo()
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestGet(t *testing.T) {
+	withRemote(t)
+	withStatfs(t, testMountInfo, map[string]unix.Statfs_t{
+		"/":             {Bsize: 4096, Frsize: 4096, Blocks: 4049370, Bfree: 800000, Bavail: 597532, Files: 1015808, Ffree: 3},
+		"/dev":          {Bsize: 4096, Blocks: 2038780},
+		"/dev/shm":      {Bsize: 4096, Blocks: 2041280},
+		"/home/my data": {Bsize: 4096, Frsize: 1024, Blocks: 1048576},
+		"/mnt/nfs":      {Bsize: 65536, Blocks: 16384},
+		"/run/empty":    {Bsize: 4096},
+	}, map[string]bool{"/mnt/stuck": true})
+
+	mounts, warnings, err := Get()
+	require.NoError(t, err)
+	require.Equal(t, []string{"could not stat /mnt/stuck: statfs timed out after 20ms"}, warnings)
+	require.Len(t, mounts, 6)
+
+	require.Greater(t, mounts[0].HealthLatencyMilliseconds, float64(0))
+	mounts[0].HealthLatencyMilliseconds = 0
+	require.Equal(t, MountInfo{
+		Name:           "/dev/sda1",
+		MountedOn:      "/",
+		SizeBytes:      4049370 * 4096,
+		UsedBytes:      (4049370 - 800000) * 4096,
+		AvailableBytes: 597532 * 4096,
+		FSType:         "ext4",
+		MountOptions:   []string{"ro", "relatime"},
+		SuperOptions:   []string{"ro", "errors=remount-ro"},
+		ReadOnly:       true,
+		MountID:        22,
+		ParentID:       1,
+		Device:         "8:1",
+		Root:           "/",
+		InodesTotal:    1015808,
+		InodesFree:     3,
+		ReservedRatio:  float64(800000-597532) / 4049370,
+		StorageStack:   testStack,
+		Classification: "local",
+		Health:         "ok",
+	}, mounts[0])
+	require.False(t, mounts[1].ReadOnly)
+	require.Nil(t, mounts[1].StorageStack)
+	require.Equal(t, uint64(1048576*1024), mounts[3].SizeBytes)
+
+	classifications := []string{}
+	for _, mount := range mounts {
+		classifications = append(classifications, mount.Classification)
+	}
+	require.Equal(t, []string{"local", "pseudo", "pseudo", "local", "network", "network"}, classifications)
+
+	// the hung mount is reported as stale, without usage
+	stuck := mounts[5]
+	require.Equal(t, "/mnt/stuck", stuck.MountedOn)
+	require.Equal(t, "stale", stuck.Health)
+	require.Equal(t, "statfs timed out after 20ms", stuck.HealthError)
+	require.Zero(t, stuck.SizeBytes)
+}
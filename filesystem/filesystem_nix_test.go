@@ -35,7 +35,7 @@ func TestSlowDf(t *testing.T) {
 	dfTimeout = 20 * time.Millisecond // test faster
 	defer func() { dfTimeout = 2 * time.Second }()
 
-	_, err := getFileSystemInfo()
+	_, err := getDfFileSystemInfo()
 	require.ErrorContains(t, err, "df failed to collect filesystem data")
 }
 
@@ -49,7 +49,7 @@ func TestOldMacosDf(t *testing.T) {
 		echo 'map -static                                        0          0         0   100%        0        0  100%   /Volumes/Large';
 	`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "975093952", "mounted_on": "/", "name": "/dev/disk0s2"},
@@ -67,7 +67,7 @@ func TestDfLinux(t *testing.T) {
 		echo 'tmpfs                   15388388        0  15388388   0% /dev/shm';
 	`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "16197480", "mounted_on": "/", "name": "/dev/root"},
@@ -83,7 +83,7 @@ func TestDfMac(t *testing.T) {
 		echo '/dev/disk1s5     488245288        20 344743840     1%       2 3447438400    0%   /System/Volumes/VM';
 	`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "488245288", "mounted_on": "/", "name": "/dev/disk1s1s1"},
@@ -98,7 +98,7 @@ func TestDfWithVolumeSpaces(t *testing.T) {
 		echo '/dev/disk5        307200    283136     24064  93% /Volumes/MySQL Workbench community-8.0.30';
 	`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "367616", "mounted_on": "/Volumes/Firefox", "name": "/dev/disk4s3"},
@@ -114,7 +114,7 @@ func TestDfWithErrors(t *testing.T) {
 		echo '/dev/disk5        307200    283136     24064  93% /Volumes/MySQL Workbench community-8.0.30';
 	`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "367616", "mounted_on": "/Volumes/Firefox", "name": "/dev/disk4s3"},
@@ -126,7 +126,7 @@ func TestFaileDfWithData(t *testing.T) {
 	// (note that this sample output is valid on both linux and darwin)
 	withDfCommand(t, "sh", "-c", `echo "Filesystem     1K-blocks      Used Available Use% Mounted on"; echo "/dev/disk1s1s1 488245288 138504332 349740956  29% /"; exit 1`)
 
-	out, err := getFileSystemInfo()
+	out, err := getDfFileSystemInfo()
 	require.NoError(t, err)
 	require.Equal(t, []interface{}{
 		map[string]string{"kb_size": "488245288", "mounted_on": "/", "name": "/dev/disk1s1s1"},
@@ -139,3 +139,81 @@ func TestGetFileSystemInfo(t *testing.T) {
 	outArray := out.([]interface{})
 	require.Greater(t, len(outArray), 0)
 }
+
+func TestDfFiltered(t *testing.T) {
+	withDfCommand(t, "sh", "-c", `
+		echo 'Filesystem             1K-blocks     Used Available Use% Mounted on';
+		echo '/dev/root               16197480 13252004   2929092  82% /';
+		echo 'devtmpfs                15381564        0  15381564   0% /dev';
+		echo 'tmpfs                   15388388        0  15388388   0% /dev/shm';
+	`)
+	options.excludePaths = patternList{"/dev/*"}
+	options.excludeDevices = patternList{"devtmpfs"}
+	defer func() { options = filterRules{} }()
+
+	out, err := getDfFileSystemInfo()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{
+		map[string]string{"kb_size": "16197480", "mounted_on": "/", "name": "/dev/root"},
+	}, out)
+}
+
+func TestDfMounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		df       string
+		legacy   []interface{}
+		mounts   []MountInfo
+		warnings []string
+	}{
+		{
+			name: "sizes",
+			df: `
+				echo 'Filesystem     1K-blocks      Used Available Use% Mounted on';
+				echo '/dev/disk4s3      367616    360928      6688  99% /Volumes/Firefox';
+				echo '/dev/disk5        307200    283136     24064  93% /Volumes/MySQL Workbench community-8.0.30';
+			`,
+			legacy: []interface{}{
+				map[string]string{"kb_size": "367616", "mounted_on": "/Volumes/Firefox", "name": "/dev/disk4s3"},
+				map[string]string{"kb_size": "307200", "mounted_on": "/Volumes/MySQL Workbench community-8.0.30", "name": "/dev/disk5"},
+			},
+			mounts: []MountInfo{
+				{Name: "/dev/disk4s3", MountedOn: "/Volumes/Firefox", SizeBytes: 367616 * 1024},
+				{Name: "/dev/disk5", MountedOn: "/Volumes/MySQL Workbench community-8.0.30", SizeBytes: 307200 * 1024},
+			},
+		},
+		{
+			// the legacy payload keeps the size as reported by df
+			name: "unparsable size",
+			df: `
+				echo 'Filesystem     1K-blocks      Used Available Use% Mounted on';
+				echo '/dev/disk4s3      367616    360928      6688  99% /Volumes/Firefox';
+				echo 'map auto_home          -         -         -    - /System/Volumes/Data/home';
+			`,
+			legacy: []interface{}{
+				map[string]string{"kb_size": "367616", "mounted_on": "/Volumes/Firefox", "name": "/dev/disk4s3"},
+				map[string]string{"kb_size": "-", "mounted_on": "/System/Volumes/Data/home", "name": "map auto_home"},
+			},
+			mounts: []MountInfo{
+				{Name: "/dev/disk4s3", MountedOn: "/Volumes/Firefox", SizeBytes: 367616 * 1024},
+				{Name: "map auto_home", MountedOn: "/System/Volumes/Data/home"},
+			},
+			warnings: []string{`could not parse the size "-" of /System/Volumes/Data/home`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withDfCommand(t, "sh", "-c", test.df)
+
+			out, err := getDfFileSystemInfo()
+			require.NoError(t, err)
+			require.Equal(t, test.legacy, out)
+
+			mounts, warnings, err := getDfMounts()
+			require.NoError(t, err)
+			require.Equal(t, test.mounts, mounts)
+			require.Equal(t, test.warnings, warnings)
+		})
+	}
+}
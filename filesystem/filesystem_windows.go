@@ -6,6 +6,7 @@
 package filesystem
 
 import (
+	"fmt"
 	"strconv"
 	"syscall"
 	"unsafe"
@@ -107,6 +108,31 @@ func getMountPoints(vol string) []string {
 }
 
 func getFileSystemInfo() (interface{}, error) {
+	mounts, warnings, err := getMounts()
+	logWarnings(warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileSystemInfo []interface{}
+	for _, mount := range mounts {
+		var capacity string
+		if 0 == mount.SizeBytes {
+			capacity = "Unknown"
+		} else {
+			capacity = strconv.FormatInt(int64(mount.SizeBytes)/1024.0, 10)
+		}
+		iface := map[string]interface{}{
+			"name":       mount.Name,
+			"kb_size":    capacity,
+			"mounted_on": mount.MountedOn,
+		}
+		fileSystemInfo = append(fileSystemInfo, iface)
+	}
+	return fileSystemInfo, nil
+}
+
+func getMounts() ([]MountInfo, []string, error) {
 	var mod = syscall.NewLazyDLL("kernel32.dll")
 	var findFirst = mod.NewProc("FindFirstVolumeW")
 	var findNext = mod.NewProc("FindNextVolumeW")
@@ -118,7 +144,8 @@ func getFileSystemInfo() (interface{}, error) {
 	fh, _, _ := findFirst.Call(uintptr(unsafe.Pointer(&buf[0])),
 		uintptr(sz))
 	var findHandle = Handle(fh)
-	var fileSystemInfo []interface{}
+	var mounts []MountInfo
+	var warnings []string
 
 	if findHandle != InvalidHandle {
 		// ignore close error
@@ -127,24 +154,26 @@ func getFileSystemInfo() (interface{}, error) {
 		moreData := true
 		for moreData {
 			outstring := convertWindowsString(buf)
-			sz, _ := getDiskSize(outstring)
-			var capacity string
-			if 0 == sz {
-				capacity = "Unknown"
-			} else {
-				capacity = strconv.FormatInt(int64(sz)/1024.0, 10)
-			}
+			size, free := getDiskSize(outstring)
 			mountpts := getMountPoints(outstring)
 			var mountName string
 			if len(mountpts) > 0 {
 				mountName = mountpts[0]
+				if 0 == size {
+					// volumes without mount points have no size
+					warnings = append(warnings, fmt.Sprintf("could not get the size of %s", mountName))
+				}
+			}
+			mount := MountInfo{
+				Name:      outstring,
+				MountedOn: mountName,
+				SizeBytes: size,
 			}
-			iface := map[string]interface{}{
-				"name":       outstring,
-				"kb_size":    capacity,
-				"mounted_on": mountName,
+			if free <= size {
+				mount.AvailableBytes = free
+				mount.UsedBytes = size - free
 			}
-			fileSystemInfo = append(fileSystemInfo, iface)
+			mounts = append(mounts, mount)
 			status, _, _ := findNext.Call(fh,
 				uintptr(unsafe.Pointer(&buf[0])),
 				uintptr(sz))
@@ -155,5 +184,5 @@ func getFileSystemInfo() (interface{}, error) {
 
 	}
 
-	return fileSystemInfo, nil
+	return mounts, warnings, nil
 }
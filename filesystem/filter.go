@@ -0,0 +1,92 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package filesystem
+
+import (
+	"flag"
+	"path"
+	"strings"
+)
+
+// patternList is a comma-separated list of patterns, usable as a flag
+type patternList []string
+
+// String returns the comma-separated list of patterns
+func (l *patternList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set adds the given comma-separated patterns to the list
+func (l *patternList) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			*l = append(*l, pattern)
+		}
+	}
+	return nil
+}
+
+// matches tells whether value matches any of the glob patterns of the list
+func (l patternList) matches(value string) bool {
+	for _, pattern := range l {
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRules selects the filesystems to collect.  Each kind of rule is
+// checked independently: a non-empty include list keeps only the matching
+// filesystems, and an exclude list drops the matching ones.
+type filterRules struct {
+	// remote also selects the network filesystems, skipped by default
+	remote         bool
+	includeTypes   patternList
+	excludeTypes   patternList
+	includePaths   patternList
+	excludePaths   patternList
+	includeDevices patternList
+	excludeDevices patternList
+}
+
+var options filterRules
+
+func init() {
+	flag.BoolVar(&options.remote, name+"-remote", false, "Also collect the network filesystems")
+	flag.Var(&options.includeTypes, name+"-include-types", "Collect only the filesystems of these types, including pseudo and network ones (Linux only, comma-separated globs, eg. 'ext4,nfs*')")
+	flag.Var(&options.excludeTypes, name+"-exclude-types", "Do not collect the filesystems of these types (Linux only, comma-separated globs, eg. 'tmpfs,squashfs')")
+	flag.Var(&options.includePaths, name+"-include-paths", "Collect only the filesystems mounted on these paths (comma-separated globs, eg. '/,/data/*')")
+	flag.Var(&options.excludePaths, name+"-exclude-paths", "Do not collect the filesystems mounted on these paths (comma-separated globs, eg. '/run/*,/snap/*')")
+	flag.Var(&options.includeDevices, name+"-include-devices", "Collect only the filesystems of these devices (comma-separated globs, eg. '/dev/sd*')")
+	flag.Var(&options.excludeDevices, name+"-exclude-devices", "Do not collect the filesystems of these devices (comma-separated globs, eg. '/dev/loop*')")
+}
+
+// keep tells whether a filesystem passes the include and exclude rules.  The
+// type rules are ignored when the type is unknown (eg. from `df`).
+func (r *filterRules) keep(fsType string, mountPoint string, device string) bool {
+	if fsType != "" {
+		if len(r.includeTypes) != 0 && !r.includeTypes.matches(fsType) {
+			return false
+		}
+		if r.excludeTypes.matches(fsType) {
+			return false
+		}
+	}
+	if len(r.includePaths) != 0 && !r.includePaths.matches(mountPoint) {
+		return false
+	}
+	if r.excludePaths.matches(mountPoint) {
+		return false
+	}
+	if len(r.includeDevices) != 0 && !r.includeDevices.matches(device) {
+		return false
+	}
+	return !r.excludeDevices.matches(device)
+}
@@ -0,0 +1,133 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// health of the mounts
+const (
+	healthOK      = "ok"
+	healthSlow    = "slow"
+	healthStale   = "stale"
+	healthROError = "ro-error"
+)
+
+var healthOptions struct {
+	slowThreshold time.Duration
+	writeDir      string
+}
+
+func init() {
+	flag.DurationVar(&healthOptions.slowThreshold, name+"-health-slow-threshold", 500*time.Millisecond, "Report the filesystems whose health probe takes longer than this duration as slow (Linux only)")
+	flag.StringVar(&healthOptions.writeDir, name+"-health-write-dir", "", "Also probe the filesystems by writing a file in this directory, relative to their mount point, when it exists (Linux only, eg. '.gohai')")
+}
+
+// errTimedOut is wrapped by the errors of the probes which timed out
+var errTimedOut = errors.New("timed out")
+
+var writeTest = writeTestFile
+
+// probe is the result of the health probe of a mount
+type probe struct {
+	stat *unix.Statfs_t
+	// statErr is the error of statfs, in which case stat is nil
+	statErr error
+	// writeErr is the error of the write test
+	writeErr error
+	health   string
+	latency  time.Duration
+}
+
+// probeMount calls statfs on a mount and, if healthOptions.writeDir exists
+// on a writable mount, writes a file in it.  Each step is bounded by
+// statfsTimeout.
+func probeMount(mount utils.MountInfo) probe {
+	start := time.Now()
+	var p probe
+	p.stat, p.statErr = statfsWithTimeout(mount.MountPoint)
+	if p.statErr == nil && healthOptions.writeDir != "" && !hasOption(mount.MountOptions, "ro") {
+		dir := filepath.Join(mount.MountPoint, healthOptions.writeDir)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			p.writeErr = writeTestWithTimeout(dir)
+		}
+	}
+	p.latency = time.Since(start)
+	p.health = healthStatus(mount, p)
+	return p
+}
+
+// healthStatus returns the health of a mount from its probe:
+//   - "stale" if statfs or the write test hung, or failed with an error
+//     meaning the mount is gone (a stale NFS handle, a dead FUSE daemon)
+//   - "ro-error" if the filesystem was remounted read-only (eg. by
+//     `errors=remount-ro`) while the mount is read-write
+//   - "slow" if the probe took longer than healthOptions.slowThreshold
+//   - "ok" otherwise
+func healthStatus(mount utils.MountInfo, p probe) string {
+	switch {
+	case isStale(p.statErr) || isStale(p.writeErr):
+		return healthStale
+	case errors.Is(p.writeErr, unix.EROFS):
+		return healthROError
+	case hasOption(mount.SuperOptions, "ro") && !hasOption(mount.MountOptions, "ro"):
+		return healthROError
+	case p.latency > healthOptions.slowThreshold:
+		return healthSlow
+	}
+	return healthOK
+}
+
+// isStale tells whether a probe error means the mount is unusable
+func isStale(err error) bool {
+	return errors.Is(err, errTimedOut) || errors.Is(err, unix.ESTALE) ||
+		errors.Is(err, unix.ENOTCONN) || errors.Is(err, unix.EIO)
+}
+
+// writeTestWithTimeout runs the write test in dir, giving up after
+// statfsTimeout
+func writeTestWithTimeout(dir string) error {
+	done := make(chan error, 1)
+	writeTestFunc := writeTest
+	go func() {
+		done <- writeTestFunc(dir)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(statfsTimeout):
+		return fmt.Errorf("write test %w after %s", errTimedOut, statfsTimeout)
+	}
+}
+
+// writeTestFile writes, syncs and removes a temporary file in dir
+func writeTestFile(dir string) error {
+	file, err := ioutil.TempFile(dir, ".gohai-health-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write([]byte("gohai\n")); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestHealthStatus(t *testing.T) {
+	rw := utils.MountInfo{MountOptions: []string{"rw"}, SuperOptions: []string{"rw"}}
+	remountedRO := utils.MountInfo{MountOptions: []string{"rw"}, SuperOptions: []string{"ro", "errors=remount-ro"}}
+	ro := utils.MountInfo{MountOptions: []string{"ro"}, SuperOptions: []string{"ro"}}
+
+	require.Equal(t, "ok", healthStatus(rw, probe{}))
+	require.Equal(t, "ok", healthStatus(ro, probe{}))
+	require.Equal(t, "slow", healthStatus(rw, probe{latency: time.Second}))
+	require.Equal(t, "stale", healthStatus(rw, probe{statErr: unix.ESTALE}))
+	require.Equal(t, "stale", healthStatus(rw, probe{statErr: unix.ENOTCONN}))
+	require.Equal(t, "stale", healthStatus(rw, probe{writeErr: errTimedOut}))
+	require.Equal(t, "ro-error", healthStatus(remountedRO, probe{}))
+	require.Equal(t, "ro-error", healthStatus(rw, probe{writeErr: &os.PathError{Op: "open", Err: unix.EROFS}}))
+	require.Equal(t, "ok", healthStatus(rw, probe{writeErr: unix.EACCES}))
+}
+
+func TestProbeMountWriteTest(t *testing.T) {
+	mountPoint := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(mountPoint, ".gohai"), 0o777))
+	withStatfs(t, "", map[string]unix.Statfs_t{mountPoint: {Bsize: 4096, Blocks: 1}}, nil)
+	oldWriteDir := healthOptions.writeDir
+	healthOptions.writeDir = ".gohai"
+	defer func() { healthOptions.writeDir = oldWriteDir }()
+
+	mount := utils.MountInfo{MountPoint: mountPoint, MountOptions: []string{"rw"}}
+	p := probeMount(mount)
+	require.NoError(t, p.writeErr)
+	require.Equal(t, "ok", p.health)
+	files, err := ioutil.ReadDir(filepath.Join(mountPoint, ".gohai"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	release := make(chan struct{})
+	defer close(release)
+	oldWriteTest := writeTest
+	defer func() { writeTest = oldWriteTest }()
+	writeTest = func(dir string) error {
+		<-release
+		return nil
+	}
+	p = probeMount(mount)
+	require.ErrorIs(t, p.writeErr, errTimedOut)
+	require.Equal(t, "stale", p.health)
+
+	// the write test is skipped on read-only mounts
+	p = probeMount(utils.MountInfo{MountPoint: mountPoint, MountOptions: []string{"ro"}})
+	require.NoError(t, p.writeErr)
+	require.Equal(t, "ok", p.health)
+}
@@ -0,0 +1,28 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+// Pool describes the storage pool behind a btrfs subvolume or a ZFS dataset.
+// All the subvolumes or datasets of a pool report the capacity of the whole
+// pool, so mounts with the same DedupeKey must only be counted once.
+type Pool struct {
+	// Type is the filesystem type: "btrfs" or "zfs"
+	Type string `json:"type"`
+	// UUID is the UUID of the btrfs filesystem (empty for ZFS, whose kstats
+	// do not report the pool GUID)
+	UUID string `json:"uuid"`
+	// Name is the label of the btrfs filesystem or the name of the ZFS pool
+	Name string `json:"name"`
+	// Dataset is the mounted btrfs subvolume or ZFS dataset
+	Dataset string `json:"dataset"`
+	// State is the state of the ZFS pool, eg. "ONLINE" or "DEGRADED"
+	State string `json:"state"`
+	// Devices lists the block devices backing the btrfs filesystem (the ZFS
+	// kstats do not report the vdevs of a pool)
+	Devices []string `json:"devices"`
+	// DedupeKey identifies the pool, eg. "btrfs:<uuid>" or "zfs:<pool>"
+	DedupeKey string `json:"dedupe_key"`
+}
@@ -0,0 +1,123 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+var btrfsPath = "/sys/fs/btrfs"
+var zfsKstatPath = "/proc/spl/kstat/zfs"
+
+// btrfsFileSystem is a btrfs filesystem registered in /sys/fs/btrfs
+type btrfsFileSystem struct {
+	uuid    string
+	label   string
+	devices []string
+}
+
+// readBtrfsFileSystems reads the btrfs filesystems of /sys/fs/btrfs/<uuid>,
+// keyed by the name of each of their devices, eg. "sda1" or "dm-0"
+func readBtrfsFileSystems() map[string]*btrfsFileSystem {
+	byDevice := map[string]*btrfsFileSystem{}
+	entries, err := ioutil.ReadDir(btrfsPath)
+	if err != nil {
+		return byDevice
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(btrfsPath, entry.Name())
+		devices, err := ioutil.ReadDir(filepath.Join(dir, "devices"))
+		if err != nil {
+			// the `features` directory
+			continue
+		}
+		fs := &btrfsFileSystem{uuid: entry.Name(), devices: []string{}}
+		if label, err := ioutil.ReadFile(filepath.Join(dir, "label")); err == nil {
+			fs.label = strings.TrimSpace(string(label))
+		}
+		for _, device := range devices {
+			fs.devices = append(fs.devices, device.Name())
+			byDevice[device.Name()] = fs
+		}
+		sort.Strings(fs.devices)
+	}
+	return byDevice
+}
+
+// findPool returns the pool of a btrfs or ZFS mount, or nil for other
+// filesystems or if the pool cannot be found
+func findPool(mount utils.MountInfo, btrfs map[string]*btrfsFileSystem) *Pool {
+	switch mount.FSType {
+	case "btrfs":
+		fs, ok := btrfs[deviceName(mount.Source)]
+		if !ok {
+			return nil
+		}
+		return &Pool{
+			Type:      "btrfs",
+			UUID:      fs.uuid,
+			Name:      fs.label,
+			Dataset:   superOption(mount.SuperOptions, "subvol"),
+			Devices:   fs.devices,
+			DedupeKey: "btrfs:" + fs.uuid,
+		}
+	case "zfs":
+		return findZFSPool(mount.Source)
+	}
+	return nil
+}
+
+// findZFSPool returns the pool of a ZFS dataset from its kstats in
+// /proc/spl/kstat/zfs/<pool>
+func findZFSPool(dataset string) *Pool {
+	pool := strings.SplitN(dataset, "/", 2)[0]
+	dir := filepath.Join(zfsKstatPath, pool)
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+
+	p := &Pool{
+		Type:      "zfs",
+		Name:      pool,
+		Dataset:   dataset,
+		Devices:   []string{},
+		DedupeKey: "zfs:" + pool,
+	}
+	if state, err := ioutil.ReadFile(filepath.Join(dir, "state")); err == nil {
+		p.State = strings.TrimSpace(string(state))
+	}
+	return p
+}
+
+// deviceName returns the name of the block device of a mount source, eg.
+// "dm-0" for /dev/mapper/vg-root, resolving the links of /dev
+func deviceName(source string) string {
+	if !strings.HasPrefix(source, "/dev/") {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(source); err == nil {
+		source = resolved
+	}
+	return filepath.Base(source)
+}
+
+// superOption returns the value of a `key=value` superblock option, or an
+// empty string
+func superOption(options []string, key string) string {
+	for _, option := range options {
+		if strings.HasPrefix(option, key+"=") {
+			return strings.TrimPrefix(option, key+"=")
+		}
+	}
+	return ""
+}
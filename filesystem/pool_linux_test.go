@@ -0,0 +1,66 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestFindPool(t *testing.T) {
+	root := t.TempDir()
+	oldBtrfs, oldZFS := btrfsPath, zfsKstatPath
+	btrfsPath, zfsKstatPath = filepath.Join(root, "btrfs"), filepath.Join(root, "zfs")
+	defer func() { btrfsPath, zfsKstatPath = oldBtrfs, oldZFS }()
+
+	for path, content := range map[string]string{
+		"btrfs/features/free_space_tree":                         "0\n",
+		"btrfs/0d2f5c1e-6a4b-4c1e-9b3a-2f1e5d6c7b8a/label":       "data\n",
+		"btrfs/0d2f5c1e-6a4b-4c1e-9b3a-2f1e5d6c7b8a/devices/sdc": "",
+		"btrfs/0d2f5c1e-6a4b-4c1e-9b3a-2f1e5d6c7b8a/devices/sdb": "",
+		"zfs/tank/state": "ONLINE\n",
+	} {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o666))
+	}
+
+	btrfs := readBtrfsFileSystems()
+	require.Len(t, btrfs, 2)
+
+	// both subvolumes of the btrfs filesystem share the same pool
+	home := findPool(utils.MountInfo{FSType: "btrfs", Source: "/dev/sdc", SuperOptions: []string{"rw", "subvolid=257", "subvol=/@home"}}, btrfs)
+	rootfs := findPool(utils.MountInfo{FSType: "btrfs", Source: "/dev/sdb", SuperOptions: []string{"rw", "subvolid=256", "subvol=/@"}}, btrfs)
+	require.Equal(t, &Pool{
+		Type:      "btrfs",
+		UUID:      "0d2f5c1e-6a4b-4c1e-9b3a-2f1e5d6c7b8a",
+		Name:      "data",
+		Dataset:   "/@home",
+		Devices:   []string{"sdb", "sdc"},
+		DedupeKey: "btrfs:0d2f5c1e-6a4b-4c1e-9b3a-2f1e5d6c7b8a",
+	}, home)
+	require.Equal(t, "/@", rootfs.Dataset)
+	require.Equal(t, home.DedupeKey, rootfs.DedupeKey)
+
+	require.Equal(t, &Pool{
+		Type:      "zfs",
+		Name:      "tank",
+		Dataset:   "tank/home/alice",
+		State:     "ONLINE",
+		Devices:   []string{},
+		DedupeKey: "zfs:tank",
+	}, findPool(utils.MountInfo{FSType: "zfs", Source: "tank/home/alice"}, btrfs))
+
+	require.Nil(t, findPool(utils.MountInfo{FSType: "zfs", Source: "rpool/ROOT"}, btrfs))
+	require.Nil(t, findPool(utils.MountInfo{FSType: "btrfs", Source: "/dev/sdd"}, btrfs))
+	require.Nil(t, findPool(utils.MountInfo{FSType: "ext4", Source: "/dev/sdb"}, btrfs))
+}
@@ -0,0 +1,39 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+// Quotas holds the state of the user, group and project quotas of a
+// filesystem
+type Quotas struct {
+	User    *QuotaState `json:"user"`
+	Group   *QuotaState `json:"group"`
+	Project *QuotaState `json:"project"`
+}
+
+// QuotaState is the state of a quota type and the usage of each ID it tracks
+type QuotaState struct {
+	// Enabled tells whether the usage is accounted
+	Enabled bool `json:"enabled"`
+	// Enforced tells whether the limits are enforced
+	Enforced bool `json:"enforced"`
+	// IDs lists the usage and limits of each user, group or project ID
+	IDs []QuotaUsage `json:"ids"`
+	// Truncated tells whether IDs were left out, see the
+	// `filesystem-quotas-max-ids` flag
+	Truncated bool `json:"truncated"`
+}
+
+// QuotaUsage is the usage and the limits of an ID.  A limit of 0 means no
+// limit.
+type QuotaUsage struct {
+	ID                  uint32 `json:"id"`
+	UsedBytes           uint64 `json:"used_bytes"`
+	BlockSoftLimitBytes uint64 `json:"block_soft_limit_bytes"`
+	BlockHardLimitBytes uint64 `json:"block_hard_limit_bytes"`
+	InodesUsed          uint64 `json:"inodes_used"`
+	InodeSoftLimit      uint64 `json:"inode_soft_limit"`
+	InodeHardLimit      uint64 `json:"inode_hard_limit"`
+}
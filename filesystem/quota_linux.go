@@ -0,0 +1,176 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"errors"
+	"flag"
+	"math"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+var quotaOptions struct {
+	enabled bool
+	maxIDs  int
+}
+
+func init() {
+	flag.BoolVar(&quotaOptions.enabled, name+"-quotas", false, "Collect the user, group and project quotas of each filesystem (Linux only, listing the usage of other IDs requires root)")
+	flag.IntVar(&quotaOptions.maxIDs, name+"-quotas-max-ids", 1000, "Maximum number of IDs listed for each quota type of a filesystem (Linux only)")
+}
+
+// quotactl commands and types, see quotactl(2)
+const (
+	qXGetQStatV    = 'X'<<8 + 8 // Q_XGETQSTATV
+	qGetNextQuota  = 0x800009   // Q_GETNEXTQUOTA, Linux 4.6+
+	usrQuota       = 0
+	grpQuota       = 1
+	prjQuota       = 2
+	qStatVVersion1 = 1 // FS_QSTATV_VERSION1
+)
+
+// quota state flags of fsQuotaStatv, named after FS_QUOTA_*
+const (
+	udqAcct = 1 << iota
+	udqEnfd
+	gdqAcct
+	gdqEnfd
+	pdqAcct
+	pdqEnfd
+)
+
+// quotaBlockBytes is the unit of the block limits (QIF_DQBLKSIZE)
+const quotaBlockBytes = 1024
+
+// fsQuotaStatv is the head of struct fs_quota_statv, filled by Q_XGETQSTATV
+// for XFS as well as for the filesystems using the generic quota code
+type fsQuotaStatv struct {
+	version   int8
+	_         uint8
+	flags     uint16
+	incoredqs uint32
+	// the quota files, time and warning limits, and padding
+	_ [152]byte
+}
+
+// nextDqblk is struct if_nextdqblk, filled by Q_GETNEXTQUOTA
+type nextDqblk struct {
+	bhardlimit uint64
+	bsoftlimit uint64
+	curspace   uint64
+	ihardlimit uint64
+	isoftlimit uint64
+	curinodes  uint64
+	btime      uint64
+	itime      uint64
+	valid      uint32
+	id         uint32
+}
+
+// quotactl calls quotactl(2), replaced in tests by a fake
+var quotactl = func(cmd uint32, special string, id uint32, addr unsafe.Pointer) error {
+	device, err := unix.BytePtrFromString(special)
+	if err != nil {
+		return err
+	}
+	_, _, errno := unix.Syscall6(unix.SYS_QUOTACTL, uintptr(cmd), uintptr(unsafe.Pointer(device)), uintptr(id), uintptr(addr), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// qcmd combines a quotactl command and a quota type, as the QCMD macro does
+func qcmd(cmd uint32, quotaType uint32) uint32 {
+	return cmd<<8 | quotaType&0xff
+}
+
+// getQuotas returns the quotas of the filesystem of a mount, or nil if the
+// mount is not backed by a block device or has no quota enabled.  Listing the
+// usage of other IDs fails with EPERM for non-root users: the quota states
+// are then still returned, with the IDs listed so far, along with the error.
+func getQuotas(mount utils.MountInfo) (*Quotas, error) {
+	if !strings.HasPrefix(mount.Source, "/dev/") {
+		return nil, nil
+	}
+
+	stat := fsQuotaStatv{version: qStatVVersion1}
+	if err := quotactl(qcmd(qXGetQStatV, usrQuota), mount.Source, 0, unsafe.Pointer(&stat)); err != nil {
+		if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EINVAL) || errors.Is(err, unix.ENOTSUP) ||
+			errors.Is(err, unix.ESRCH) || errors.Is(err, unix.ENOENT) || errors.Is(err, unix.ENOTBLK) {
+			// the filesystem does not support quotas
+			return nil, nil
+		}
+		return nil, err
+	}
+	if stat.flags&(udqAcct|gdqAcct|pdqAcct) == 0 {
+		return nil, nil
+	}
+
+	quotas := &Quotas{}
+	var permErr error
+	for _, q := range []struct {
+		state              **QuotaState
+		quotaType          uint32
+		acctFlag, enfdFlag uint16
+	}{
+		{&quotas.User, usrQuota, udqAcct, udqEnfd},
+		{&quotas.Group, grpQuota, gdqAcct, gdqEnfd},
+		{&quotas.Project, prjQuota, pdqAcct, pdqEnfd},
+	} {
+		var err error
+		*q.state, err = getQuotaState(mount.Source, q.quotaType, stat.flags&q.acctFlag != 0, stat.flags&q.enfdFlag != 0)
+		if errors.Is(err, unix.EPERM) {
+			permErr = err
+		} else if err != nil {
+			return quotas, err
+		}
+	}
+	return quotas, permErr
+}
+
+// getQuotaState lists the usage of each ID of a quota type, up to
+// quotaOptions.maxIDs
+func getQuotaState(device string, quotaType uint32, accounted bool, enforced bool) (*QuotaState, error) {
+	state := &QuotaState{Enabled: accounted, Enforced: enforced, IDs: []QuotaUsage{}}
+	if !accounted {
+		return state, nil
+	}
+
+	for id := uint32(0); ; {
+		var dq nextDqblk
+		err := quotactl(qcmd(qGetNextQuota, quotaType), device, id, unsafe.Pointer(&dq))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		}
+		if err != nil {
+			return state, err
+		}
+		if len(state.IDs) >= quotaOptions.maxIDs {
+			state.Truncated = true
+			break
+		}
+		state.IDs = append(state.IDs, QuotaUsage{
+			ID:                  dq.id,
+			UsedBytes:           dq.curspace,
+			BlockSoftLimitBytes: dq.bsoftlimit * quotaBlockBytes,
+			BlockHardLimitBytes: dq.bhardlimit * quotaBlockBytes,
+			InodesUsed:          dq.curinodes,
+			InodeSoftLimit:      dq.isoftlimit,
+			InodeHardLimit:      dq.ihardlimit,
+		})
+		if dq.id == math.MaxUint32 {
+			break
+		}
+		id = dq.id + 1
+	}
+	return state, nil
+}
@@ -0,0 +1,151 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// fakeQuotaDevice is the quota state of a device for fakeQuotactl
+type fakeQuotaDevice struct {
+	err error
+	// nextErr is only returned by qGetNextQuota
+	nextErr error
+	flags   uint16
+	// the dquots of each quota type, sorted by ID
+	dquots map[uint32][]nextDqblk
+}
+
+// withFakeQuotactl replaces quotactl by a fake serving the given devices
+func withFakeQuotactl(t *testing.T, devices map[string]fakeQuotaDevice) {
+	oldQuotactl, oldOptions := quotactl, quotaOptions
+	quotaOptions.maxIDs = 1000
+	quotactl = func(cmd uint32, special string, id uint32, addr unsafe.Pointer) error {
+		device, ok := devices[special]
+		if !ok {
+			return unix.ENOTBLK
+		}
+		if device.err != nil {
+			return device.err
+		}
+		switch cmd >> 8 {
+		case qXGetQStatV:
+			stat := (*fsQuotaStatv)(addr)
+			require.Equal(t, int8(qStatVVersion1), stat.version)
+			stat.flags = device.flags
+			return nil
+		case qGetNextQuota:
+			if device.nextErr != nil {
+				return device.nextErr
+			}
+			for _, dq := range device.dquots[cmd&0xff] {
+				if dq.id >= id {
+					*(*nextDqblk)(addr) = dq
+					return nil
+				}
+			}
+			return unix.ENOENT
+		}
+		return unix.EINVAL
+	}
+	t.Cleanup(func() { quotactl, quotaOptions = oldQuotactl, oldOptions })
+}
+
+func TestQuotaStructSizes(t *testing.T) {
+	// the sizes of struct fs_quota_statv and struct if_nextdqblk
+	require.Equal(t, uintptr(160), unsafe.Sizeof(fsQuotaStatv{}))
+	require.Equal(t, uintptr(72), unsafe.Sizeof(nextDqblk{}))
+}
+
+func TestGetQuotas(t *testing.T) {
+	withFakeQuotactl(t, map[string]fakeQuotaDevice{
+		// XFS with enforced project quotas and accounted user quotas
+		"/dev/sdb1": {
+			flags: udqAcct | pdqAcct | pdqEnfd,
+			dquots: map[uint32][]nextDqblk{
+				usrQuota: {{id: 0, curspace: 1 << 20, curinodes: 12}, {id: 1000, curspace: 4096, curinodes: 1}},
+				prjQuota: {{id: 42, bsoftlimit: 1 << 20, bhardlimit: 2 << 20, curspace: 300 << 20, isoftlimit: 1000, ihardlimit: 2000, curinodes: 150}},
+			},
+		},
+		// ext4 without quotas
+		"/dev/sda1": {},
+		"/dev/sdc1": {err: unix.EPERM},
+		// listing the IDs requires root
+		"/dev/sdd1": {flags: udqAcct | udqEnfd | pdqAcct, nextErr: unix.EPERM},
+	})
+
+	quotas, err := getQuotas(utils.MountInfo{Source: "/dev/sdb1"})
+	require.NoError(t, err)
+	require.Equal(t, &Quotas{
+		User: &QuotaState{Enabled: true, IDs: []QuotaUsage{
+			{ID: 0, UsedBytes: 1 << 20, InodesUsed: 12},
+			{ID: 1000, UsedBytes: 4096, InodesUsed: 1},
+		}},
+		Group: &QuotaState{IDs: []QuotaUsage{}},
+		Project: &QuotaState{Enabled: true, Enforced: true, IDs: []QuotaUsage{{
+			ID:                  42,
+			UsedBytes:           300 << 20,
+			BlockSoftLimitBytes: 1 << 30,
+			BlockHardLimitBytes: 2 << 30,
+			InodesUsed:          150,
+			InodeSoftLimit:      1000,
+			InodeHardLimit:      2000,
+		}}},
+	}, quotas)
+
+	quotaOptions.maxIDs = 1
+	quotas, err = getQuotas(utils.MountInfo{Source: "/dev/sdb1"})
+	require.NoError(t, err)
+	require.Len(t, quotas.User.IDs, 1)
+	require.True(t, quotas.User.Truncated)
+	require.False(t, quotas.Project.Truncated)
+
+	for _, source := range []string{"/dev/sda1", "/dev/loop0", "tmpfs"} {
+		quotas, err = getQuotas(utils.MountInfo{Source: source})
+		require.NoError(t, err, source)
+		require.Nil(t, quotas, source)
+	}
+
+	_, err = getQuotas(utils.MountInfo{Source: "/dev/sdc1"})
+	require.ErrorIs(t, err, unix.EPERM)
+
+	// the states are kept when the IDs cannot be listed
+	quotas, err = getQuotas(utils.MountInfo{Source: "/dev/sdd1"})
+	require.ErrorIs(t, err, unix.EPERM)
+	require.Equal(t, &Quotas{
+		User:    &QuotaState{Enabled: true, Enforced: true, IDs: []QuotaUsage{}},
+		Group:   &QuotaState{IDs: []QuotaUsage{}},
+		Project: &QuotaState{Enabled: true, IDs: []QuotaUsage{}},
+	}, quotas)
+}
+
+func TestGetPartialQuotas(t *testing.T) {
+	withStatfs(t, testMountInfo, map[string]unix.Statfs_t{
+		"/":             {Bsize: 4096, Blocks: 4049370},
+		"/dev":          {Bsize: 4096, Blocks: 2038780},
+		"/dev/shm":      {Bsize: 4096, Blocks: 2041280},
+		"/home/my data": {Bsize: 4096, Blocks: 1048576},
+		"/run/empty":    {Bsize: 4096},
+	}, nil)
+	withFakeQuotactl(t, map[string]fakeQuotaDevice{
+		"/dev/sda1": {flags: udqAcct | udqEnfd, nextErr: unix.EPERM},
+		"/dev/sda2": {},
+	})
+	quotaOptions.enabled = true
+
+	mounts, warnings, err := Get()
+	require.NoError(t, err)
+	require.Equal(t, []string{"could not read the quotas of /: operation not permitted"}, warnings)
+	require.Equal(t, "/", mounts[0].MountedOn)
+	require.Equal(t, &QuotaState{Enabled: true, Enforced: true, IDs: []QuotaUsage{}}, mounts[0].Quotas.User)
+	require.Nil(t, mounts[3].Quotas)
+}
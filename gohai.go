@@ -21,6 +21,7 @@ import (
 
 	// project
 	"github.com/DataDog/gohai/cpu"
+	"github.com/DataDog/gohai/disks"
 	"github.com/DataDog/gohai/filesystem"
 	"github.com/DataDog/gohai/memory"
 	"github.com/DataDog/gohai/network"
@@ -39,8 +40,12 @@ type SelectedCollectors map[string]struct{}
 
 var collectors = []Collector{
 	&cpu.Cpu{},
+	&cpu.Details{},
+	&disks.Disks{},
 	&filesystem.FileSystem{},
+	&filesystem.Details{},
 	&memory.Memory{},
+	&memory.Details{},
 	&network.Network{},
 	&platform.Platform{},
 	&processes.Processes{},
@@ -182,3 +182,23 @@ func TestGohaiSerialization(t *testing.T) {
 		assert.NotEmpty(t, payload.Platform.Family)
 	}
 }
+
+func TestGohaiLegacyPayloadIsFlat(t *testing.T) {
+	gohai, err := Collect()
+	assert.NoError(t, err)
+
+	gohaiJSON, err := json.Marshal(gohai)
+	assert.NoError(t, err)
+
+	// the legacy cpu, filesystem and memory payloads only hold strings, the
+	// structured details live under their own keys
+	var payload struct {
+		CPU        map[string]string   `json:"cpu"`
+		Filesystem []map[string]string `json:"filesystem"`
+		Memory     map[string]string   `json:"memory"`
+	}
+	assert.NoError(t, json.Unmarshal(gohaiJSON, &payload))
+	assert.NotEmpty(t, payload.CPU)
+	assert.NotEmpty(t, payload.Filesystem)
+	assert.NotEmpty(t, payload.Memory)
+}
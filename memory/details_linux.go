@@ -0,0 +1,43 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import "fmt"
+
+// getMemoryDetails fills the structured, Linux-only fields of the given
+// Memory.  Failures are reported as warnings, leaving the matching fields
+// empty.
+func getMemoryDetails(m *Memory, warnings *[]string) {
+	if memInfo, err := readMemInfo(); err == nil {
+		m.MemInfo = memInfo
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not read /proc/meminfo: %s", err))
+	}
+
+	if hugePages, err := getHugePages(); err == nil {
+		m.HugePages = hugePages
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect huge pages: %s", err))
+	}
+
+	if inventory, err := getMemoryInventory(); err == nil {
+		m.Inventory = inventory
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect the memory modules: %s", err))
+	}
+
+	if swap, err := getSwap(); err == nil {
+		m.Swap = swap
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not read /proc/swaps: %s", err))
+	}
+
+	if effective, err := GetEffectiveMemory(0); err == nil {
+		m.EffectiveMemory = effective
+	} else {
+		*warnings = append(*warnings, fmt.Sprintf("could not collect the effective memory: %s", err))
+	}
+}
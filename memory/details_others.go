@@ -0,0 +1,26 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package memory
+
+import "errors"
+
+// getMemoryDetails is a no-op: the structured details are only collected on
+// Linux
+func getMemoryDetails(m *Memory, warnings *[]string) {}
+
+// GetEffectiveMemory returns an error: cgroups only exist on Linux
+func GetEffectiveMemory(pid int) (*EffectiveMemory, error) {
+	return nil, errors.New("cgroups are only supported on Linux")
+}
+
+// GetPressure returns an error: pressure stall information and vmstat
+// counters only exist on Linux
+func GetPressure(cgroupPath string) (*Pressure, error) {
+	return nil, errors.New("memory pressure is only supported on Linux")
+}
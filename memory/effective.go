@@ -0,0 +1,36 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+// EffectiveMemory describes the memory actually available to a process once
+// the limits of its cgroup are applied, as returned by GetEffectiveMemory
+type EffectiveMemory struct {
+	// CgroupVersion is the version of the cgroup hierarchy the limits were
+	// read from (1 or 2)
+	CgroupVersion int `json:"cgroup_version"`
+	// CgroupPath is the cgroup of the process, relative to the hierarchy root
+	CgroupPath string `json:"cgroup_path"`
+	// LimitBytes is the hard memory limit (memory.max on v2,
+	// memory.limit_in_bytes on v1), or -1 if it is unlimited
+	LimitBytes int64 `json:"limit_bytes"`
+	// HighBytes is the throttling limit (memory.high, v2 only), or -1 if it
+	// is unlimited
+	HighBytes int64 `json:"high_bytes"`
+	// SwapLimitBytes is the swap limit (memory.swap.max, v2 only), or -1 if
+	// it is unlimited
+	SwapLimitBytes int64 `json:"swap_limit_bytes"`
+	// MemSwapLimitBytes is the limit of memory and swap combined
+	// (memory.memsw.limit_in_bytes, v1 only), or -1 if it is unlimited
+	MemSwapLimitBytes int64 `json:"memsw_limit_bytes"`
+	// UsageBytes is the memory used by the cgroup (memory.current on v2,
+	// memory.usage_in_bytes on v1)
+	UsageBytes uint64 `json:"usage_bytes"`
+	// HostTotalBytes is the total memory of the host
+	HostTotalBytes uint64 `json:"host_total_bytes"`
+	// TotalBytes is the effective memory: the smallest of LimitBytes and
+	// HostTotalBytes
+	TotalBytes uint64 `json:"total_bytes"`
+}
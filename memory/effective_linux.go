@@ -0,0 +1,109 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// cgroupV1Unlimited is the threshold above which v1 limits are considered
+// unlimited: v1 reports no limit as the largest page-aligned int64
+// (9223372036854771712 with 4KB pages)
+const cgroupV1Unlimited = 1 << 62
+
+// GetEffectiveMemory returns the memory available to the process with the
+// given PID (the current process if pid is 0), based on its cgroup:
+//   - v1: memory.limit_in_bytes, memory.memsw.limit_in_bytes and
+//     memory.usage_in_bytes
+//   - v2: memory.max, memory.high, memory.swap.max and memory.current
+//
+// Limits are checked in every ancestor of the cgroup, as they also apply to
+// descendants, and the most restrictive one is reported.
+func GetEffectiveMemory(pid int) (*EffectiveMemory, error) {
+	cgroups, err := utils.GetCgroups(prefix, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryCgroup := utils.FindCgroup(cgroups, "memory")
+	if memoryCgroup == nil {
+		return nil, errors.New("no cgroup hierarchy found for the memory controller")
+	}
+
+	e := &EffectiveMemory{
+		CgroupVersion:     memoryCgroup.Version,
+		CgroupPath:        memoryCgroup.Path,
+		LimitBytes:        -1,
+		HighBytes:         -1,
+		SwapLimitBytes:    -1,
+		MemSwapLimitBytes: -1,
+	}
+
+	limits := map[string]*int64{
+		"memory.limit_in_bytes":       &e.LimitBytes,
+		"memory.memsw.limit_in_bytes": &e.MemSwapLimitBytes,
+	}
+	usageFile := "memory.usage_in_bytes"
+	if memoryCgroup.Version == 2 {
+		limits = map[string]*int64{
+			"memory.max":      &e.LimitBytes,
+			"memory.high":     &e.HighBytes,
+			"memory.swap.max": &e.SwapLimitBytes,
+		}
+		usageFile = "memory.current"
+	}
+
+	for _, dir := range memoryCgroup.Ancestors() {
+		for file, current := range limits {
+			limit, ok := readCgroupLimit(filepath.Join(dir, file))
+			if ok && limit >= 0 && (*current < 0 || limit < *current) {
+				*current = limit
+			}
+		}
+	}
+
+	if content, err := ioutil.ReadFile(filepath.Join(memoryCgroup.Dir, usageFile)); err == nil {
+		e.UsageBytes, _ = strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	}
+
+	if memInfo, err := readMemInfo(); err == nil {
+		e.HostTotalBytes = memInfo.MemTotalBytes
+	}
+	e.TotalBytes = e.HostTotalBytes
+	if e.LimitBytes >= 0 && (e.TotalBytes == 0 || uint64(e.LimitBytes) < e.TotalBytes) {
+		e.TotalBytes = uint64(e.LimitBytes)
+	}
+
+	return e, nil
+}
+
+// readCgroupLimit reads a memory limit of a cgroup.  The limit is -1 when
+// the cgroup is not limited, which is written `max` on v2.
+func readCgroupLimit(path string) (int64, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(string(content))
+	if value == "max" {
+		return -1, true
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if limit >= cgroupV1Unlimited {
+		return -1, true
+	}
+	return int64(limit), true
+}
@@ -0,0 +1,90 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestEffectiveMemoryV1(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/meminfo": "MemTotal:       16384000 kB\n",
+		"proc/self/cgroup": `4:memory:/docker/abcd
+0::/
+`,
+		"proc/self/mountinfo":                                          "30 25 0:26 / /sys/fs/cgroup/memory rw - cgroup cgroup rw,memory\n",
+		"sys/fs/cgroup/memory/memory.limit_in_bytes":                   "9223372036854771712\n",
+		"sys/fs/cgroup/memory/docker/memory.limit_in_bytes":            "9223372036854771712\n",
+		"sys/fs/cgroup/memory/docker/abcd/memory.limit_in_bytes":       "2147483648\n",
+		"sys/fs/cgroup/memory/docker/abcd/memory.memsw.limit_in_bytes": "4294967296\n",
+		"sys/fs/cgroup/memory/docker/abcd/memory.usage_in_bytes":       "104857600\n",
+	})
+
+	effective, err := GetEffectiveMemory(0)
+	require.NoError(t, err)
+	require.Equal(t, &EffectiveMemory{
+		CgroupVersion:     1,
+		CgroupPath:        "/docker/abcd",
+		LimitBytes:        2147483648,
+		HighBytes:         -1,
+		SwapLimitBytes:    -1,
+		MemSwapLimitBytes: 4294967296,
+		UsageBytes:        104857600,
+		HostTotalBytes:    16384000 * 1024,
+		TotalBytes:        2147483648,
+	}, effective)
+}
+
+func TestEffectiveMemoryV2(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/meminfo":        "MemTotal:       16384000 kB\n",
+		"proc/self/cgroup":    "0::/kubepods.slice/pod1.slice/container.scope\n",
+		"proc/self/mountinfo": "35 24 0:30 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n",
+		// the limit of the pod applies to the container
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/memory.max":      "max\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/memory.high":     "805306368\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/memory.swap.max": "0\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/container.scope/memory.current":  "52428800\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/memory.max":                      "1073741824\n",
+		"sys/fs/cgroup/kubepods.slice/pod1.slice/memory.high":                     "max\n",
+		"sys/fs/cgroup/kubepods.slice/memory.max":                                 "8589934592\n",
+	})
+
+	effective, err := GetEffectiveMemory(0)
+	require.NoError(t, err)
+	require.Equal(t, &EffectiveMemory{
+		CgroupVersion:     2,
+		CgroupPath:        "/kubepods.slice/pod1.slice/container.scope",
+		LimitBytes:        1073741824,
+		HighBytes:         805306368,
+		SwapLimitBytes:    0,
+		MemSwapLimitBytes: -1,
+		UsageBytes:        52428800,
+		HostTotalBytes:    16384000 * 1024,
+		TotalBytes:        1073741824,
+	}, effective)
+}
+
+func TestEffectiveMemoryUnlimited(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/meminfo":                        "MemTotal:       16384000 kB\n",
+		"proc/1234/cgroup":                    "0::/user.slice\n",
+		"proc/self/mountinfo":                 "35 24 0:30 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n",
+		"sys/fs/cgroup/user.slice/memory.max": "max\n",
+	})
+
+	effective, err := GetEffectiveMemory(1234)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), effective.LimitBytes)
+	require.Equal(t, uint64(16384000*1024), effective.TotalBytes)
+}
@@ -0,0 +1,99 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+// HugePages describes the huge page pools, the transparent huge page
+// configuration and the kernel samepage merging state of the host
+type HugePages struct {
+	// Pools lists the huge page pools of each page size
+	Pools []HugePagePool `json:"pools"`
+	// NodePools lists the huge page pools of each NUMA node
+	NodePools []NodeHugePagePools `json:"node_pools"`
+	// Transparent is the transparent huge page configuration, nil if THP is
+	// not supported by the kernel
+	Transparent *TransparentHugePages `json:"transparent"`
+	// KSM is the kernel samepage merging state, nil if KSM is not supported
+	// by the kernel
+	KSM *KSM `json:"ksm"`
+}
+
+// HugePagePool is the pool of huge pages of a given size
+type HugePagePool struct {
+	// PageSizeBytes is the size of the pages of the pool
+	PageSizeBytes uint64 `json:"page_size_bytes"`
+	// Total is the number of pages in the pool (nr_hugepages)
+	Total uint64 `json:"total"`
+	// Free is the number of pages not allocated
+	Free uint64 `json:"free"`
+	// Reserved is the number of pages reserved but not yet allocated (not
+	// reported per NUMA node)
+	Reserved uint64 `json:"reserved"`
+	// Surplus is the number of pages allocated over Total
+	Surplus uint64 `json:"surplus"`
+	// Overcommit is the maximum number of surplus pages (not reported per
+	// NUMA node)
+	Overcommit uint64 `json:"overcommit"`
+}
+
+// NodeHugePagePools lists the huge page pools of a NUMA node
+type NodeHugePagePools struct {
+	// Node is the NUMA node ID
+	Node uint64 `json:"node"`
+	// Pools lists the huge page pools of each page size on this node
+	Pools []HugePagePool `json:"pools"`
+}
+
+// TransparentHugePages is the transparent huge page (THP) configuration from
+// /sys/kernel/mm/transparent_hugepage
+type TransparentHugePages struct {
+	// Enabled is the THP mode: "always", "madvise" or "never"
+	Enabled string `json:"enabled"`
+	// Defrag is the defragmentation mode (eg. "madvise", "defer")
+	Defrag string `json:"defrag"`
+	// ShmemEnabled is the THP mode of shmem and tmpfs
+	ShmemEnabled string `json:"shmem_enabled,omitempty"`
+	// PMDSizeBytes is the size of a PMD-mapped huge page
+	PMDSizeBytes uint64 `json:"pmd_size_bytes"`
+	// UseZeroPage tells whether the huge zero page is used for read faults
+	UseZeroPage bool `json:"use_zero_page"`
+	// KhugepagedDefrag tells whether khugepaged defragments memory to
+	// collapse pages
+	KhugepagedDefrag bool `json:"khugepaged_defrag"`
+	// Sizes lists the mode of each multi-size THP page size (kernels 6.8+)
+	Sizes []TransparentHugePageSize `json:"sizes"`
+}
+
+// TransparentHugePageSize is the mode of a multi-size THP page size
+type TransparentHugePageSize struct {
+	// PageSizeBytes is the page size
+	PageSizeBytes uint64 `json:"page_size_bytes"`
+	// Enabled is the mode of this size: "always", "inherit", "madvise" or
+	// "never"
+	Enabled string `json:"enabled"`
+}
+
+// KSM is the kernel samepage merging state from /sys/kernel/mm/ksm
+type KSM struct {
+	// Run is the KSM state: "stopped", "running" or "unmerging"
+	Run string `json:"run"`
+	// PagesShared is the number of shared pages in use
+	PagesShared uint64 `json:"pages_shared"`
+	// PagesSharing is the number of sites sharing these pages
+	PagesSharing uint64 `json:"pages_sharing"`
+	// PagesUnshared is the number of pages unique but repeatedly checked
+	PagesUnshared uint64 `json:"pages_unshared"`
+	// PagesVolatile is the number of pages changing too fast to be merged
+	PagesVolatile uint64 `json:"pages_volatile"`
+	// FullScans is the number of times all mergeable areas were scanned
+	FullScans uint64 `json:"full_scans"`
+	// PagesToScan is the number of pages scanned before ksmd sleeps
+	PagesToScan uint64 `json:"pages_to_scan"`
+	// SleepMilliseconds is how long ksmd sleeps between scans
+	SleepMilliseconds uint64 `json:"sleep_millisecs"`
+	// MergeAcrossNodes tells whether pages of different NUMA nodes can be
+	// merged
+	MergeAcrossNodes bool `json:"merge_across_nodes"`
+}
@@ -0,0 +1,176 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// hugePagesDirRegex recognizes directories named `hugepages-2048kB`
+var hugePagesDirRegex = regexp.MustCompile("^hugepages-([0-9]+)kB$")
+
+// nodeNRegex recognizes directories named `nodeNN`
+var nodeNRegex = regexp.MustCompile("^node([0-9]+)$")
+
+// ksmRunStates maps the values of /sys/kernel/mm/ksm/run to a state
+var ksmRunStates = map[uint64]string{
+	0: "stopped",
+	1: "running",
+	2: "unmerging",
+}
+
+// getHugePages reads the huge page pools from /sys/kernel/mm/hugepages and
+// /sys/devices/system/node/nodeN/hugepages, the THP configuration from
+// /sys/kernel/mm/transparent_hugepage and the KSM state from
+// /sys/kernel/mm/ksm.  Kernels built without hugetlbfs have no pool.
+func getHugePages() (*HugePages, error) {
+	pools, err := readHugePagePools("/sys/kernel/mm/hugepages")
+	if os.IsNotExist(err) {
+		pools = []HugePagePool{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	h := &HugePages{
+		Pools:       pools,
+		NodePools:   []NodeHugePagePools{},
+		Transparent: readTransparentHugePages(),
+		KSM:         readKSM(),
+	}
+
+	if dirents, err := os.ReadDir(prefix + "/sys/devices/system/node"); err == nil {
+		for _, dirent := range dirents {
+			submatches := nodeNRegex.FindStringSubmatch(dirent.Name())
+			if submatches == nil {
+				continue
+			}
+			node, err := strconv.ParseUint(submatches[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			nodePools, err := readHugePagePools("/sys/devices/system/node/" + dirent.Name() + "/hugepages")
+			if err != nil {
+				continue
+			}
+			h.NodePools = append(h.NodePools, NodeHugePagePools{Node: node, Pools: nodePools})
+		}
+		sort.Slice(h.NodePools, func(i, j int) bool { return h.NodePools[i].Node < h.NodePools[j].Node })
+	}
+
+	return h, nil
+}
+
+// readHugePagePools reads the pools in the `hugepages-<size>kB` directories
+// of dir, sorted by page size
+func readHugePagePools(dir string) ([]HugePagePool, error) {
+	dirents, err := os.ReadDir(prefix + dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []HugePagePool{}
+	for _, dirent := range dirents {
+		submatches := hugePagesDirRegex.FindStringSubmatch(dirent.Name())
+		if submatches == nil {
+			continue
+		}
+		size, err := strconv.ParseUint(submatches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		poolDir := dir + "/" + dirent.Name() + "/"
+		pool := HugePagePool{PageSizeBytes: size * 1024}
+		pool.Total, _ = readUint(poolDir + "nr_hugepages")
+		pool.Free, _ = readUint(poolDir + "free_hugepages")
+		pool.Reserved, _ = readUint(poolDir + "resv_hugepages")
+		pool.Surplus, _ = readUint(poolDir + "surplus_hugepages")
+		pool.Overcommit, _ = readUint(poolDir + "nr_overcommit_hugepages")
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].PageSizeBytes < pools[j].PageSizeBytes })
+
+	return pools, nil
+}
+
+// readTransparentHugePages reads the THP configuration, or returns nil if
+// the kernel does not support THP
+func readTransparentHugePages() *TransparentHugePages {
+	const dir = "/sys/kernel/mm/transparent_hugepage/"
+
+	enabled, ok := readString(dir + "enabled")
+	if !ok {
+		return nil
+	}
+
+	thp := &TransparentHugePages{
+		Enabled: selectedMode(enabled),
+		Sizes:   []TransparentHugePageSize{},
+	}
+	if defrag, ok := readString(dir + "defrag"); ok {
+		thp.Defrag = selectedMode(defrag)
+	}
+	if shmemEnabled, ok := readString(dir + "shmem_enabled"); ok {
+		thp.ShmemEnabled = selectedMode(shmemEnabled)
+	}
+	thp.PMDSizeBytes, _ = readUint(dir + "hpage_pmd_size")
+	if useZeroPage, ok := readUint(dir + "use_zero_page"); ok {
+		thp.UseZeroPage = useZeroPage != 0
+	}
+	if defrag, ok := readUint(dir + "khugepaged/defrag"); ok {
+		thp.KhugepagedDefrag = defrag != 0
+	}
+
+	if dirents, err := os.ReadDir(prefix + dir); err == nil {
+		for _, dirent := range dirents {
+			submatches := hugePagesDirRegex.FindStringSubmatch(dirent.Name())
+			if submatches == nil {
+				continue
+			}
+			size, err := strconv.ParseUint(submatches[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			if sizeEnabled, ok := readString(dir + dirent.Name() + "/enabled"); ok {
+				thp.Sizes = append(thp.Sizes, TransparentHugePageSize{PageSizeBytes: size * 1024, Enabled: selectedMode(sizeEnabled)})
+			}
+		}
+		sort.Slice(thp.Sizes, func(i, j int) bool { return thp.Sizes[i].PageSizeBytes < thp.Sizes[j].PageSizeBytes })
+	}
+
+	return thp
+}
+
+// readKSM reads the KSM state, or returns nil if the kernel does not support
+// KSM
+func readKSM() *KSM {
+	const dir = "/sys/kernel/mm/ksm/"
+
+	run, ok := readUint(dir + "run")
+	if !ok {
+		return nil
+	}
+
+	ksm := &KSM{Run: ksmRunStates[run]}
+	if ksm.Run == "" {
+		ksm.Run = strconv.FormatUint(run, 10)
+	}
+	ksm.PagesShared, _ = readUint(dir + "pages_shared")
+	ksm.PagesSharing, _ = readUint(dir + "pages_sharing")
+	ksm.PagesUnshared, _ = readUint(dir + "pages_unshared")
+	ksm.PagesVolatile, _ = readUint(dir + "pages_volatile")
+	ksm.FullScans, _ = readUint(dir + "full_scans")
+	ksm.PagesToScan, _ = readUint(dir + "pages_to_scan")
+	ksm.SleepMilliseconds, _ = readUint(dir + "sleep_millisecs")
+	if mergeAcrossNodes, ok := readUint(dir + "merge_across_nodes"); ok {
+		ksm.MergeAcrossNodes = mergeAcrossNodes != 0
+	}
+
+	return ksm
+}
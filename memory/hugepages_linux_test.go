@@ -0,0 +1,93 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetHugePages(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages":                   "512\n",
+		"sys/kernel/mm/hugepages/hugepages-2048kB/free_hugepages":                 "100\n",
+		"sys/kernel/mm/hugepages/hugepages-2048kB/resv_hugepages":                 "20\n",
+		"sys/kernel/mm/hugepages/hugepages-2048kB/surplus_hugepages":              "0\n",
+		"sys/kernel/mm/hugepages/hugepages-2048kB/nr_overcommit_hugepages":        "64\n",
+		"sys/kernel/mm/hugepages/hugepages-1048576kB/nr_hugepages":                "2\n",
+		"sys/kernel/mm/hugepages/hugepages-1048576kB/free_hugepages":              "2\n",
+		"sys/devices/system/node/node0/hugepages/hugepages-2048kB/nr_hugepages":   "256\n",
+		"sys/devices/system/node/node0/hugepages/hugepages-2048kB/free_hugepages": "40\n",
+		"sys/devices/system/node/node1/hugepages/hugepages-2048kB/nr_hugepages":   "256\n",
+		"sys/devices/system/node/node1/hugepages/hugepages-2048kB/free_hugepages": "60\n",
+		"sys/kernel/mm/transparent_hugepage/enabled":                              "[always] madvise never\n",
+		"sys/kernel/mm/transparent_hugepage/defrag":                               "always defer defer+madvise [madvise] never\n",
+		"sys/kernel/mm/transparent_hugepage/hpage_pmd_size":                       "2097152\n",
+		"sys/kernel/mm/transparent_hugepage/use_zero_page":                        "1\n",
+		"sys/kernel/mm/transparent_hugepage/khugepaged/defrag":                    "0\n",
+		"sys/kernel/mm/transparent_hugepage/hugepages-64kB/enabled":               "always inherit [madvise] never\n",
+		"sys/kernel/mm/ksm/run":                "1\n",
+		"sys/kernel/mm/ksm/pages_shared":       "1000\n",
+		"sys/kernel/mm/ksm/pages_sharing":      "5000\n",
+		"sys/kernel/mm/ksm/merge_across_nodes": "0\n",
+	})
+
+	hugePages, err := getHugePages()
+	require.NoError(t, err)
+	require.Equal(t, &HugePages{
+		Pools: []HugePagePool{
+			{PageSizeBytes: 2 * 1024 * 1024, Total: 512, Free: 100, Reserved: 20, Overcommit: 64},
+			{PageSizeBytes: 1024 * 1024 * 1024, Total: 2, Free: 2},
+		},
+		NodePools: []NodeHugePagePools{
+			{Node: 0, Pools: []HugePagePool{{PageSizeBytes: 2 * 1024 * 1024, Total: 256, Free: 40}}},
+			{Node: 1, Pools: []HugePagePool{{PageSizeBytes: 2 * 1024 * 1024, Total: 256, Free: 60}}},
+		},
+		Transparent: &TransparentHugePages{
+			Enabled:      "always",
+			Defrag:       "madvise",
+			PMDSizeBytes: 2 * 1024 * 1024,
+			UseZeroPage:  true,
+			Sizes:        []TransparentHugePageSize{{PageSizeBytes: 64 * 1024, Enabled: "madvise"}},
+		},
+		KSM: &KSM{
+			Run:          "running",
+			PagesShared:  1000,
+			PagesSharing: 5000,
+		},
+	}, hugePages)
+}
+
+func TestGetHugePagesUnsupported(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages": "0\n",
+	})
+
+	hugePages, err := getHugePages()
+	require.NoError(t, err)
+	require.Nil(t, hugePages.Transparent)
+	require.Nil(t, hugePages.KSM)
+	require.Empty(t, hugePages.NodePools)
+}
+
+func TestGetHugePagesWithoutHugetlbfs(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"sys/kernel/mm/transparent_hugepage/enabled": "always [madvise] never\n",
+		"sys/kernel/mm/ksm/run":                      "0\n",
+	})
+
+	hugePages, err := getHugePages()
+	require.NoError(t, err)
+	require.Equal(t, []HugePagePool{}, hugePages.Pools)
+	require.Equal(t, "madvise", hugePages.Transparent.Enabled)
+	require.Equal(t, "stopped", hugePages.KSM.Run)
+}
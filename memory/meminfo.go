@@ -0,0 +1,196 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+// MemInfo is the content of /proc/meminfo.  Sizes are converted to bytes,
+// while the HugePages_* entries are numbers of pages.  Entries are 0 when
+// not reported by the running kernel.  Each field documents its entry in
+// /proc/meminfo, along with the unit it is reported in.
+type MemInfo struct {
+	// MemTotalBytes is the usable RAM (MemTotal, kB)
+	MemTotalBytes uint64 `json:"mem_total_bytes"`
+	// MemFreeBytes is the unused RAM (MemFree, kB)
+	MemFreeBytes uint64 `json:"mem_free_bytes"`
+	// MemAvailableBytes is the RAM available to new applications without swapping (MemAvailable, kB)
+	MemAvailableBytes uint64 `json:"mem_available_bytes"`
+	// BuffersBytes is the RAM used by the block device buffers (Buffers, kB)
+	BuffersBytes uint64 `json:"buffers_bytes"`
+	// CachedBytes is the RAM used by the page cache (Cached, kB)
+	CachedBytes uint64 `json:"cached_bytes"`
+	// SwapCachedBytes is the swapped out memory still in RAM (SwapCached, kB)
+	SwapCachedBytes uint64 `json:"swap_cached_bytes"`
+	// ActiveBytes is the recently used memory (Active, kB)
+	ActiveBytes uint64 `json:"active_bytes"`
+	// InactiveBytes is the memory reclaimed first (Inactive, kB)
+	InactiveBytes uint64 `json:"inactive_bytes"`
+	// ActiveAnonBytes is the active anonymous memory (Active(anon), kB)
+	ActiveAnonBytes uint64 `json:"active_anon_bytes"`
+	// InactiveAnonBytes is the inactive anonymous memory (Inactive(anon), kB)
+	InactiveAnonBytes uint64 `json:"inactive_anon_bytes"`
+	// ActiveFileBytes is the active file-backed memory (Active(file), kB)
+	ActiveFileBytes uint64 `json:"active_file_bytes"`
+	// InactiveFileBytes is the inactive file-backed memory (Inactive(file), kB)
+	InactiveFileBytes uint64 `json:"inactive_file_bytes"`
+	// UnevictableBytes is the memory which cannot be reclaimed (Unevictable, kB)
+	UnevictableBytes uint64 `json:"unevictable_bytes"`
+	// MlockedBytes is the memory locked with mlock() (Mlocked, kB)
+	MlockedBytes uint64 `json:"mlocked_bytes"`
+	// SwapTotalBytes is the swap space (SwapTotal, kB)
+	SwapTotalBytes uint64 `json:"swap_total_bytes"`
+	// SwapFreeBytes is the unused swap space (SwapFree, kB)
+	SwapFreeBytes uint64 `json:"swap_free_bytes"`
+	// ZswapBytes is the size of the zswap pool (Zswap, kB)
+	ZswapBytes uint64 `json:"zswap_bytes"`
+	// ZswappedBytes is the memory stored in zswap, uncompressed (Zswapped, kB)
+	ZswappedBytes uint64 `json:"zswapped_bytes"`
+	// DirtyBytes is the memory waiting to be written to disk (Dirty, kB)
+	DirtyBytes uint64 `json:"dirty_bytes"`
+	// WritebackBytes is the memory being written to disk (Writeback, kB)
+	WritebackBytes uint64 `json:"writeback_bytes"`
+	// AnonPagesBytes is the anonymous memory mapped in user space (AnonPages, kB)
+	AnonPagesBytes uint64 `json:"anon_pages_bytes"`
+	// MappedBytes is the memory of the mmap()ed files (Mapped, kB)
+	MappedBytes uint64 `json:"mapped_bytes"`
+	// ShmemBytes is the shared memory, including tmpfs (Shmem, kB)
+	ShmemBytes uint64 `json:"shmem_bytes"`
+	// KReclaimableBytes is the kernel memory reclaimable under pressure (KReclaimable, kB)
+	KReclaimableBytes uint64 `json:"kreclaimable_bytes"`
+	// SlabBytes is the memory of the kernel slab caches (Slab, kB)
+	SlabBytes uint64 `json:"slab_bytes"`
+	// SReclaimableBytes is the reclaimable part of Slab (SReclaimable, kB)
+	SReclaimableBytes uint64 `json:"sreclaimable_bytes"`
+	// SUnreclaimBytes is the unreclaimable part of Slab (SUnreclaim, kB)
+	SUnreclaimBytes uint64 `json:"sunreclaim_bytes"`
+	// KernelStackBytes is the memory of the kernel stacks (KernelStack, kB)
+	KernelStackBytes uint64 `json:"kernel_stack_bytes"`
+	// PageTablesBytes is the memory of the page tables (PageTables, kB)
+	PageTablesBytes uint64 `json:"page_tables_bytes"`
+	// SecPageTablesBytes is the memory of the secondary page tables, eg. KVM's (SecPageTables, kB)
+	SecPageTablesBytes uint64 `json:"sec_page_tables_bytes"`
+	// NFSUnstableBytes is always 0 since Linux 5.15 (NFS_Unstable, kB)
+	NFSUnstableBytes uint64 `json:"nfs_unstable_bytes"`
+	// BounceBytes is the memory of the block device bounce buffers (Bounce, kB)
+	BounceBytes uint64 `json:"bounce_bytes"`
+	// WritebackTmpBytes is the memory of the FUSE writeback buffers (WritebackTmp, kB)
+	WritebackTmpBytes uint64 `json:"writeback_tmp_bytes"`
+	// CommitLimitBytes is the memory allocatable in strict overcommit mode (CommitLimit, kB)
+	CommitLimitBytes uint64 `json:"commit_limit_bytes"`
+	// CommittedASBytes is the memory allocated by all processes (Committed_AS, kB)
+	CommittedASBytes uint64 `json:"committed_as_bytes"`
+	// VmallocTotalBytes is the size of the vmalloc area (VmallocTotal, kB)
+	VmallocTotalBytes uint64 `json:"vmalloc_total_bytes"`
+	// VmallocUsedBytes is the used part of the vmalloc area (VmallocUsed, kB)
+	VmallocUsedBytes uint64 `json:"vmalloc_used_bytes"`
+	// VmallocChunkBytes is always 0 since Linux 4.4 (VmallocChunk, kB)
+	VmallocChunkBytes uint64 `json:"vmalloc_chunk_bytes"`
+	// PercpuBytes is the memory of the per-CPU allocator (Percpu, kB)
+	PercpuBytes uint64 `json:"percpu_bytes"`
+	// HardwareCorruptedBytes is the memory poisoned by hardware errors (HardwareCorrupted, kB)
+	HardwareCorruptedBytes uint64 `json:"hardware_corrupted_bytes"`
+	// AnonHugePagesBytes is the anonymous memory in transparent huge pages (AnonHugePages, kB)
+	AnonHugePagesBytes uint64 `json:"anon_huge_pages_bytes"`
+	// ShmemHugePagesBytes is the shared memory in transparent huge pages (ShmemHugePages, kB)
+	ShmemHugePagesBytes uint64 `json:"shmem_huge_pages_bytes"`
+	// ShmemPmdMappedBytes is the shared memory mapped with huge pages (ShmemPmdMapped, kB)
+	ShmemPmdMappedBytes uint64 `json:"shmem_pmd_mapped_bytes"`
+	// FileHugePagesBytes is the page cache in transparent huge pages (FileHugePages, kB)
+	FileHugePagesBytes uint64 `json:"file_huge_pages_bytes"`
+	// FilePmdMappedBytes is the page cache mapped with huge pages (FilePmdMapped, kB)
+	FilePmdMappedBytes uint64 `json:"file_pmd_mapped_bytes"`
+	// CmaTotalBytes is the memory reserved for the contiguous allocator (CmaTotal, kB)
+	CmaTotalBytes uint64 `json:"cma_total_bytes"`
+	// CmaFreeBytes is the free part of CmaTotal (CmaFree, kB)
+	CmaFreeBytes uint64 `json:"cma_free_bytes"`
+	// HugePagesTotal is the size of the default huge page pool (HugePages_Total, pages)
+	HugePagesTotal uint64 `json:"huge_pages_total"`
+	// HugePagesFree is the unallocated part of the pool (HugePages_Free, pages)
+	HugePagesFree uint64 `json:"huge_pages_free"`
+	// HugePagesRsvd is the reserved but unallocated part of the pool (HugePages_Rsvd, pages)
+	HugePagesRsvd uint64 `json:"huge_pages_rsvd"`
+	// HugePagesSurp is the number of surplus pages above the pool (HugePages_Surp, pages)
+	HugePagesSurp uint64 `json:"huge_pages_surp"`
+	// HugePageSizeBytes is the default huge page size (Hugepagesize, kB)
+	HugePageSizeBytes uint64 `json:"huge_page_size_bytes"`
+	// HugetlbBytes is the memory of the huge pages of all sizes (Hugetlb, kB)
+	HugetlbBytes uint64 `json:"hugetlb_bytes"`
+	// DirectMap4kBytes is the kernel direct map in 4 kB pages (DirectMap4k, kB)
+	DirectMap4kBytes uint64 `json:"direct_map_4k_bytes"`
+	// DirectMap2MBytes is the kernel direct map in 2 MB pages (DirectMap2M, kB)
+	DirectMap2MBytes uint64 `json:"direct_map_2m_bytes"`
+	// DirectMap4MBytes is the kernel direct map in 4 MB pages (DirectMap4M, kB)
+	DirectMap4MBytes uint64 `json:"direct_map_4m_bytes"`
+	// DirectMap1GBytes is the kernel direct map in 1 GB pages (DirectMap1G, kB)
+	DirectMap1GBytes uint64 `json:"direct_map_1g_bytes"`
+
+	// Other holds the entries of /proc/meminfo which are not known to this
+	// version of gohai, indexed by their name in the file.  Values with a
+	// kB unit are converted to bytes.
+	Other map[string]uint64 `json:"other"`
+}
+
+// fields returns pointers to the fields of m, indexed by their name in
+// /proc/meminfo
+func (m *MemInfo) fields() map[string]*uint64 {
+	return map[string]*uint64{
+		"MemTotal":          &m.MemTotalBytes,
+		"MemFree":           &m.MemFreeBytes,
+		"MemAvailable":      &m.MemAvailableBytes,
+		"Buffers":           &m.BuffersBytes,
+		"Cached":            &m.CachedBytes,
+		"SwapCached":        &m.SwapCachedBytes,
+		"Active":            &m.ActiveBytes,
+		"Inactive":          &m.InactiveBytes,
+		"Active(anon)":      &m.ActiveAnonBytes,
+		"Inactive(anon)":    &m.InactiveAnonBytes,
+		"Active(file)":      &m.ActiveFileBytes,
+		"Inactive(file)":    &m.InactiveFileBytes,
+		"Unevictable":       &m.UnevictableBytes,
+		"Mlocked":           &m.MlockedBytes,
+		"SwapTotal":         &m.SwapTotalBytes,
+		"SwapFree":          &m.SwapFreeBytes,
+		"Zswap":             &m.ZswapBytes,
+		"Zswapped":          &m.ZswappedBytes,
+		"Dirty":             &m.DirtyBytes,
+		"Writeback":         &m.WritebackBytes,
+		"AnonPages":         &m.AnonPagesBytes,
+		"Mapped":            &m.MappedBytes,
+		"Shmem":             &m.ShmemBytes,
+		"KReclaimable":      &m.KReclaimableBytes,
+		"Slab":              &m.SlabBytes,
+		"SReclaimable":      &m.SReclaimableBytes,
+		"SUnreclaim":        &m.SUnreclaimBytes,
+		"KernelStack":       &m.KernelStackBytes,
+		"PageTables":        &m.PageTablesBytes,
+		"SecPageTables":     &m.SecPageTablesBytes,
+		"NFS_Unstable":      &m.NFSUnstableBytes,
+		"Bounce":            &m.BounceBytes,
+		"WritebackTmp":      &m.WritebackTmpBytes,
+		"CommitLimit":       &m.CommitLimitBytes,
+		"Committed_AS":      &m.CommittedASBytes,
+		"VmallocTotal":      &m.VmallocTotalBytes,
+		"VmallocUsed":       &m.VmallocUsedBytes,
+		"VmallocChunk":      &m.VmallocChunkBytes,
+		"Percpu":            &m.PercpuBytes,
+		"HardwareCorrupted": &m.HardwareCorruptedBytes,
+		"AnonHugePages":     &m.AnonHugePagesBytes,
+		"ShmemHugePages":    &m.ShmemHugePagesBytes,
+		"ShmemPmdMapped":    &m.ShmemPmdMappedBytes,
+		"FileHugePages":     &m.FileHugePagesBytes,
+		"FilePmdMapped":     &m.FilePmdMappedBytes,
+		"CmaTotal":          &m.CmaTotalBytes,
+		"CmaFree":           &m.CmaFreeBytes,
+		"HugePages_Total":   &m.HugePagesTotal,
+		"HugePages_Free":    &m.HugePagesFree,
+		"HugePages_Rsvd":    &m.HugePagesRsvd,
+		"HugePages_Surp":    &m.HugePagesSurp,
+		"Hugepagesize":      &m.HugePageSizeBytes,
+		"Hugetlb":           &m.HugetlbBytes,
+		"DirectMap4k":       &m.DirectMap4kBytes,
+		"DirectMap2M":       &m.DirectMap2MBytes,
+		"DirectMap4M":       &m.DirectMap4MBytes,
+		"DirectMap1G":       &m.DirectMap1GBytes,
+	}
+}
@@ -0,0 +1,60 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readMemInfo reads and parses /proc/meminfo, whose lines use the format
+// `MemTotal:        6158152 kB`, or `HugePages_Total:       0` for counts
+func readMemInfo() (*MemInfo, error) {
+	file, err := os.Open(prefix + "/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m := &MemInfo{Other: map[string]uint64{}}
+	fields := m.fields()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pair := strings.SplitN(scanner.Text(), ":", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(pair[0])
+		values := strings.Fields(pair[1])
+		if len(values) == 0 {
+			continue
+		}
+
+		value, err := strconv.ParseUint(values[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse /proc/meminfo line %q: %s", scanner.Text(), err)
+		}
+		if len(values) > 1 && values[1] == "kB" {
+			value *= 1024
+		}
+
+		if field, ok := fields[key]; ok {
+			*field = value
+		} else {
+			m.Other[key] = value
+		}
+	}
+
+	if scanner.Err() != nil {
+		return nil, scanner.Err()
+	}
+
+	return m, nil
+}
@@ -0,0 +1,74 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestReadMemInfo(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/meminfo": `MemTotal:        6158152 kB
+MemFree:         3760040 kB
+MemAvailable:    5602776 kB
+Buffers:           71276 kB
+Cached:          1949584 kB
+Active(anon):         12 kB
+SwapTotal:       1048576 kB
+Slab:             108372 kB
+SReclaimable:      85312 kB
+SUnreclaim:        23060 kB
+Committed_AS:     339324 kB
+VmallocTotal:   34359738367 kB
+HugePages_Total:       4
+HugePages_Free:        2
+Hugepagesize:       2048 kB
+Hugetlb:            8192 kB
+Unaccepted:         1024 kB
+Balloon:               0 kB
+FutureCount:           3
+`,
+	})
+
+	memInfo, err := readMemInfo()
+	require.NoError(t, err)
+	require.Equal(t, &MemInfo{
+		MemTotalBytes:     6158152 * 1024,
+		MemFreeBytes:      3760040 * 1024,
+		MemAvailableBytes: 5602776 * 1024,
+		BuffersBytes:      71276 * 1024,
+		CachedBytes:       1949584 * 1024,
+		ActiveAnonBytes:   12 * 1024,
+		SwapTotalBytes:    1048576 * 1024,
+		SlabBytes:         108372 * 1024,
+		SReclaimableBytes: 85312 * 1024,
+		SUnreclaimBytes:   23060 * 1024,
+		CommittedASBytes:  339324 * 1024,
+		VmallocTotalBytes: 34359738367 * 1024,
+		HugePagesTotal:    4,
+		HugePagesFree:     2,
+		HugePageSizeBytes: 2048 * 1024,
+		HugetlbBytes:      8192 * 1024,
+		Other: map[string]uint64{
+			"Unaccepted":  1024 * 1024,
+			"Balloon":     0,
+			"FutureCount": 3,
+		},
+	}, memInfo)
+}
+
+func TestReadMemInfoInvalid(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{"proc/meminfo": "MemTotal:        lots kB\n"})
+
+	_, err := readMemInfo()
+	require.Error(t, err)
+}
@@ -6,16 +6,47 @@
 // Package memory regroups collecting information about the memory
 package memory
 
+import (
+	"flag"
+
+	log "github.com/cihub/seelog"
+)
+
 // Memory holds memory metadata about the host
 type Memory struct {
 	// TotalBytes is the total memory for the host in byte
 	TotalBytes uint64
 	// SwapTotalBytes is the swap memory size in byte (Unix only)
 	SwapTotalBytes uint64
+
+	// MemInfo is the full content of /proc/meminfo (Linux only)
+	MemInfo *MemInfo
+	// HugePages describes the huge page pools and the THP and KSM
+	// configuration (Linux only)
+	HugePages *HugePages
+	// Inventory lists the memory arrays and modules from the SMBIOS tables
+	// (Linux only, requires root)
+	Inventory *MemoryInventory
+	// Swap lists the swap devices, the zram devices and the zswap
+	// configuration (Linux only)
+	Swap *Swap
+	// EffectiveMemory describes the memory available to the current process
+	// once its cgroup limits are applied (Linux only)
+	EffectiveMemory *EffectiveMemory
 }
 
 const name = "memory"
 
+var options struct {
+	pressure       bool
+	pressureCgroup string
+}
+
+func init() {
+	flag.BoolVar(&options.pressure, name+"-pressure", false, "Collect the pressure stall information, OOM kills and reclaim counters (Linux only)")
+	flag.StringVar(&options.pressureCgroup, name+"-pressure-cgroup", "", "Also collect the pressure stall information of this cgroup v2, eg. '/system.slice/foo.service' (Linux only, implies -"+name+"-pressure)")
+}
+
 // Name returns the name of the package
 func (memory *Memory) Name() string {
 	return name
@@ -29,6 +60,51 @@ func (memory *Memory) Collect() (result interface{}, err error) {
 	return
 }
 
+// Details collects the structured memory details which do not fit in the
+// flat legacy payload of Memory, under their own "memory_details" key
+type Details struct{}
+
+// Name returns the name of the collector
+func (details *Details) Name() string {
+	return name + "_details"
+}
+
+// Collect collects the structured memory details.
+// Returns an object which can be converted to a JSON or an error if nothing could be collected.
+// Tries to collect as much information as possible.
+func (details *Details) Collect() (result interface{}, err error) {
+	// any warnings are dropped as the payload has no room for them
+	m := &Memory{}
+	getMemoryDetails(m, &[]string{})
+
+	info := make(map[string]interface{})
+	if m.MemInfo != nil {
+		info["meminfo"] = m.MemInfo
+	}
+	if m.HugePages != nil {
+		info["hugepages"] = m.HugePages
+	}
+	if m.Inventory != nil {
+		info["inventory"] = m.Inventory
+	}
+	if m.Swap != nil {
+		info["swap"] = m.Swap
+	}
+	if m.EffectiveMemory != nil {
+		info["effective_memory"] = m.EffectiveMemory
+	}
+
+	if options.pressure || options.pressureCgroup != "" {
+		if pressure, err := GetPressure(options.pressureCgroup); err == nil {
+			info["pressure"] = pressure
+		} else {
+			log.Warnf("[%s] could not collect memory pressure: %s", details.Name(), err)
+		}
+	}
+
+	return info, nil
+}
+
 // Get returns a Memory struct already initialized, a list of warnings and an error. The method will try to collect as much
 // metadata as possible, an error is returned if nothing could be collected. The list of warnings contains errors if
 // some metadata could not be collected.
@@ -46,8 +122,11 @@ func Get() (*Memory, []string, error) {
 		return nil, nil, err
 	}
 
-	return &Memory{
+	m := &Memory{
 		TotalBytes:     mem,
 		SwapTotalBytes: swap,
-	}, warnings, nil
+	}
+	getMemoryDetails(m, &warnings)
+
+	return m, warnings, nil
 }
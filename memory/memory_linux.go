@@ -22,7 +22,7 @@ var memMap = map[string]string{
 }
 
 func getMemoryInfo() (memoryInfo map[string]string, err error) {
-	file, err := os.Open("/proc/meminfo")
+	file, err := os.Open(prefix + "/proc/meminfo")
 
 	if err != nil {
 		return
@@ -0,0 +1,85 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+// Pressure holds the dynamic memory signals of the host: the pressure stall
+// information (PSI), the OOM kills and the reclaim counters
+type Pressure struct {
+	// Memory is the memory PSI from /proc/pressure/memory, nil if PSI is not
+	// enabled
+	Memory *PressureStall `json:"memory"`
+	// CPU is the CPU PSI from /proc/pressure/cpu, nil if PSI is not enabled
+	CPU *PressureStall `json:"cpu"`
+	// IO is the IO PSI from /proc/pressure/io, nil if PSI is not enabled
+	IO *PressureStall `json:"io"`
+	// Cgroup is the PSI of the requested cgroup, nil if none was requested
+	Cgroup *CgroupPressure `json:"cgroup,omitempty"`
+	// VMStat holds the OOM kill and reclaim counters from /proc/vmstat
+	VMStat VMStat `json:"vmstat"`
+}
+
+// CgroupPressure is the pressure stall information of a cgroup (v2 only)
+type CgroupPressure struct {
+	// Path is the cgroup, relative to the root of the cgroup v2 hierarchy
+	Path string `json:"path"`
+	// Memory is the memory PSI from memory.pressure
+	Memory *PressureStall `json:"memory"`
+	// CPU is the CPU PSI from cpu.pressure
+	CPU *PressureStall `json:"cpu"`
+	// IO is the IO PSI from io.pressure
+	IO *PressureStall `json:"io"`
+}
+
+// PressureStall is the pressure stall information of a resource
+type PressureStall struct {
+	// Some is the share of time at least one task was stalled on the
+	// resource
+	Some *PressureStats `json:"some"`
+	// Full is the share of time all non-idle tasks were stalled on the
+	// resource simultaneously, nil on kernels not reporting it
+	Full *PressureStats `json:"full"`
+}
+
+// PressureStats are the stall averages and total of a PSI line
+type PressureStats struct {
+	// Avg10 is the percentage of stalled time over the last 10 seconds
+	Avg10 float64 `json:"avg10"`
+	// Avg60 is the percentage of stalled time over the last 60 seconds
+	Avg60 float64 `json:"avg60"`
+	// Avg300 is the percentage of stalled time over the last 300 seconds
+	Avg300 float64 `json:"avg300"`
+	// TotalMicroseconds is the total stalled time since boot
+	TotalMicroseconds uint64 `json:"total_us"`
+}
+
+// VMStat holds the OOM kill and reclaim counters of /proc/vmstat, since boot.
+// Counters split per zone or per memory type on some kernels are summed.
+type VMStat struct {
+	// OOMKill is the number of processes killed by the OOM killer (oom_kill)
+	OOMKill uint64 `json:"oom_kill"`
+	// PgFault is the number of page faults (pgfault)
+	PgFault uint64 `json:"pgfault"`
+	// PgMajFault is the number of major page faults, requiring IO
+	// (pgmajfault)
+	PgMajFault uint64 `json:"pgmajfault"`
+	// PgScanKswapd is the number of pages scanned by kswapd
+	PgScanKswapd uint64 `json:"pgscan_kswapd"`
+	// PgScanDirect is the number of pages scanned by direct reclaim
+	PgScanDirect uint64 `json:"pgscan_direct"`
+	// PgStealKswapd is the number of pages reclaimed by kswapd
+	PgStealKswapd uint64 `json:"pgsteal_kswapd"`
+	// PgStealDirect is the number of pages reclaimed by direct reclaim
+	PgStealDirect uint64 `json:"pgsteal_direct"`
+	// PswpIn is the number of pages swapped in (pswpin)
+	PswpIn uint64 `json:"pswpin"`
+	// PswpOut is the number of pages swapped out (pswpout)
+	PswpOut uint64 `json:"pswpout"`
+	// AllocStall is the number of allocations stalled in direct reclaim
+	AllocStall uint64 `json:"allocstall"`
+	// CompactStall is the number of allocations stalled in direct
+	// compaction (compact_stall)
+	CompactStall uint64 `json:"compact_stall"`
+}
@@ -0,0 +1,179 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// vmstatCounters maps the prefixes of the /proc/vmstat counters to the
+// VMStat field they are summed into.  Prefixes catch the per-zone
+// (pgscan_kswapd_normal, allocstall_movable, ...) variants of older and newer
+// kernels; pgscan_direct_throttle counts throttled reclaimers rather than
+// pages and is skipped.
+var vmstatCounters = []struct {
+	prefix string
+	field  func(*VMStat) *uint64
+}{
+	{"oom_kill", func(v *VMStat) *uint64 { return &v.OOMKill }},
+	{"pgfault", func(v *VMStat) *uint64 { return &v.PgFault }},
+	{"pgmajfault", func(v *VMStat) *uint64 { return &v.PgMajFault }},
+	{"pgscan_kswapd", func(v *VMStat) *uint64 { return &v.PgScanKswapd }},
+	{"pgscan_direct", func(v *VMStat) *uint64 { return &v.PgScanDirect }},
+	{"pgsteal_kswapd", func(v *VMStat) *uint64 { return &v.PgStealKswapd }},
+	{"pgsteal_direct", func(v *VMStat) *uint64 { return &v.PgStealDirect }},
+	{"pswpin", func(v *VMStat) *uint64 { return &v.PswpIn }},
+	{"pswpout", func(v *VMStat) *uint64 { return &v.PswpOut }},
+	{"allocstall", func(v *VMStat) *uint64 { return &v.AllocStall }},
+	{"compact_stall", func(v *VMStat) *uint64 { return &v.CompactStall }},
+}
+
+// GetPressure returns the pressure stall information of the host from
+// /proc/pressure and its OOM kill and reclaim counters from /proc/vmstat.
+// When cgroupPath is not empty, the pressure of that cgroup, relative to the
+// root of the cgroup v2 hierarchy, is also returned.
+func GetPressure(cgroupPath string) (*Pressure, error) {
+	vmstat, err := readVMStat()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pressure{
+		Memory: readPressureStall("/proc/pressure/memory"),
+		CPU:    readPressureStall("/proc/pressure/cpu"),
+		IO:     readPressureStall("/proc/pressure/io"),
+		VMStat: vmstat,
+	}
+
+	if cgroupPath != "" {
+		p.Cgroup, err = getCgroupPressure(cgroupPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// getCgroupPressure reads the PSI files of the given cgroup v2
+func getCgroupPressure(cgroupPath string) (*CgroupPressure, error) {
+	cgroups, err := utils.GetCgroups(prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mountDir := ""
+	for _, cgroup := range cgroups {
+		if cgroup.Version == 2 && cgroup.MountDir != "" {
+			mountDir = cgroup.MountDir
+			break
+		}
+	}
+	if mountDir == "" {
+		return nil, errors.New("the cgroup v2 hierarchy is not mounted")
+	}
+
+	// the directories returned by utils.GetCgroups already include the
+	// prefix, which readString adds again
+	cgroupPath = path.Clean("/" + cgroupPath)
+	dir := strings.TrimPrefix(mountDir, prefix) + cgroupPath
+	if _, err := os.Stat(prefix + dir); err != nil {
+		return nil, err
+	}
+
+	return &CgroupPressure{
+		Path:   cgroupPath,
+		Memory: readPressureStall(dir + "/memory.pressure"),
+		CPU:    readPressureStall(dir + "/cpu.pressure"),
+		IO:     readPressureStall(dir + "/io.pressure"),
+	}, nil
+}
+
+// readPressureStall parses a PSI file, or returns nil if it cannot be read
+// (PSI is disabled or not supported by the kernel):
+//
+//	some avg10=0.00 avg60=0.12 avg300=0.05 total=123456
+//	full avg10=0.00 avg60=0.03 avg300=0.01 total=45678
+func readPressureStall(file string) *PressureStall {
+	content, ok := readString(file)
+	if !ok {
+		return nil
+	}
+
+	stall := &PressureStall{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		stats := &PressureStats{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			value := kv[1]
+			switch kv[0] {
+			case "avg10":
+				stats.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stats.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stats.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				stats.TotalMicroseconds, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			stall.Some = stats
+		case "full":
+			stall.Full = stats
+		}
+	}
+
+	return stall
+}
+
+// readVMStat sums the counters of /proc/vmstat into a VMStat
+func readVMStat() (VMStat, error) {
+	vmstat := VMStat{}
+
+	file, err := os.Open(prefix + "/proc/vmstat")
+	if err != nil {
+		return vmstat, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil || fields[0] == "pgscan_direct_throttle" {
+			continue
+		}
+		for _, counter := range vmstatCounters {
+			if fields[0] == counter.prefix || strings.HasPrefix(fields[0], counter.prefix+"_") {
+				*counter.field(&vmstat) += value
+				break
+			}
+		}
+	}
+
+	return vmstat, scanner.Err()
+}
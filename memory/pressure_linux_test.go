@@ -0,0 +1,102 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+const testVMStat = `nr_free_pages 1862354
+pgfault 987654321
+pgmajfault 12345
+pswpin 100
+pswpout 250
+pgsteal_kswapd 40000
+pgsteal_direct 2000
+pgscan_kswapd 50000
+pgscan_direct 3000
+pgscan_direct_throttle 7
+allocstall_dma 0
+allocstall_dma32 1
+allocstall_normal 20
+allocstall_movable 3
+compact_stall 5
+oom_kill 2
+`
+
+func TestGetPressure(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/vmstat": testVMStat,
+		"proc/pressure/memory": `some avg10=1.50 avg60=0.75 avg300=0.20 total=123456
+full avg10=0.50 avg60=0.25 avg300=0.05 total=45678
+`,
+		"proc/pressure/cpu": "some avg10=12.00 avg60=8.00 avg300=4.00 total=9876543\n",
+		"proc/pressure/io": `some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+`,
+	})
+
+	pressure, err := GetPressure("")
+	require.NoError(t, err)
+	require.Equal(t, &Pressure{
+		Memory: &PressureStall{
+			Some: &PressureStats{Avg10: 1.5, Avg60: 0.75, Avg300: 0.2, TotalMicroseconds: 123456},
+			Full: &PressureStats{Avg10: 0.5, Avg60: 0.25, Avg300: 0.05, TotalMicroseconds: 45678},
+		},
+		CPU: &PressureStall{
+			Some: &PressureStats{Avg10: 12, Avg60: 8, Avg300: 4, TotalMicroseconds: 9876543},
+		},
+		IO: &PressureStall{
+			Some: &PressureStats{},
+			Full: &PressureStats{},
+		},
+		VMStat: VMStat{
+			OOMKill:       2,
+			PgFault:       987654321,
+			PgMajFault:    12345,
+			PgScanKswapd:  50000,
+			PgScanDirect:  3000,
+			PgStealKswapd: 40000,
+			PgStealDirect: 2000,
+			PswpIn:        100,
+			PswpOut:       250,
+			AllocStall:    24,
+			CompactStall:  5,
+		},
+	}, pressure)
+}
+
+func TestGetPressureCgroup(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/vmstat":         "oom_kill 0\n",
+		"proc/self/cgroup":    "0::/user.slice\n",
+		"proc/self/mountinfo": "35 24 0:30 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw\n",
+		"sys/fs/cgroup/system.slice/foo.service/memory.pressure": `some avg10=3.00 avg60=2.00 avg300=1.00 total=1000
+full avg10=2.00 avg60=1.00 avg300=0.50 total=500
+`,
+	})
+
+	// PSI is disabled system-wide, /proc/pressure does not exist
+	pressure, err := GetPressure("system.slice/foo.service/")
+	require.NoError(t, err)
+	require.Nil(t, pressure.Memory)
+	require.Equal(t, &CgroupPressure{
+		Path: "/system.slice/foo.service",
+		Memory: &PressureStall{
+			Some: &PressureStats{Avg10: 3, Avg60: 2, Avg300: 1, TotalMicroseconds: 1000},
+			Full: &PressureStats{Avg10: 2, Avg60: 1, Avg300: 0.5, TotalMicroseconds: 500},
+		},
+	}, pressure.Cgroup)
+
+	_, err = GetPressure("/system.slice/missing.service")
+	require.Error(t, err)
+}
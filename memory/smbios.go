@@ -0,0 +1,413 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// MemoryInventory lists the physical memory arrays and modules of the host,
+// as described by the SMBIOS tables
+//
+//nolint:revive
+type MemoryInventory struct {
+	// Arrays lists the physical memory arrays (SMBIOS type 16)
+	Arrays []MemoryArray `json:"arrays"`
+	// Modules lists the memory devices (SMBIOS type 17), including empty
+	// slots
+	Modules []MemoryModule `json:"modules"`
+}
+
+// MemoryArray is a physical memory array, ie. a group of memory slots
+//
+//nolint:revive
+type MemoryArray struct {
+	// Handle is the SMBIOS handle of the array, referenced by its modules
+	Handle uint16 `json:"handle"`
+	// Location is where the array is (eg. "System board or motherboard")
+	Location string `json:"location"`
+	// Use is the function of the array (eg. "System memory")
+	Use string `json:"use"`
+	// ErrorCorrection is the error correction scheme of the array (eg.
+	// "Multi-bit ECC", "None")
+	ErrorCorrection string `json:"error_correction"`
+	// MaxCapacityBytes is the maximum memory capacity of the array
+	MaxCapacityBytes uint64 `json:"max_capacity_bytes"`
+	// Slots is the number of memory slots of the array
+	Slots uint16 `json:"slots"`
+}
+
+// MemoryModule is a memory device, typically a DIMM slot
+//
+//nolint:revive
+type MemoryModule struct {
+	// ArrayHandle is the handle of the MemoryArray the module belongs to
+	ArrayHandle uint16 `json:"array_handle"`
+	// Locator is the label of the slot (eg. "P0 CHANNEL A DIMM 0")
+	Locator string `json:"locator"`
+	// BankLocator is the label of the bank of the slot
+	BankLocator string `json:"bank_locator"`
+	// SizeBytes is the size of the module, 0 if the slot is empty
+	SizeBytes uint64 `json:"size_bytes"`
+	// FormFactor is the form factor of the module (eg. "DIMM", "SODIMM")
+	FormFactor string `json:"form_factor"`
+	// Type is the memory type (eg. "DDR4", "DDR5")
+	Type string `json:"type"`
+	// SpeedMTs is the maximum speed of the module, in MT/s
+	SpeedMTs uint32 `json:"speed_mts"`
+	// ConfiguredSpeedMTs is the speed the module runs at, in MT/s
+	ConfiguredSpeedMTs uint32 `json:"configured_speed_mts"`
+	// Manufacturer is the manufacturer of the module
+	Manufacturer string `json:"manufacturer"`
+	// PartNumber is the part number of the module
+	PartNumber string `json:"part_number"`
+	// SerialNumber is the serial number of the module
+	SerialNumber string `json:"serial_number"`
+	// AssetTag is the asset tag of the module
+	AssetTag string `json:"asset_tag"`
+	// Rank is the number of ranks of the module, 0 if unknown
+	Rank uint8 `json:"rank"`
+	// TotalWidthBits is the width of the module including error correction
+	// bits, 0 if unknown
+	TotalWidthBits uint16 `json:"total_width_bits"`
+	// DataWidthBits is the data width of the module, 0 if unknown
+	DataWidthBits uint16 `json:"data_width_bits"`
+	// ECC tells whether the module carries error correction bits, ie. is
+	// wider than its data width
+	ECC bool `json:"ecc"`
+	// ConfiguredVoltageMillivolts is the voltage the module runs at, 0 if
+	// unknown
+	ConfiguredVoltageMillivolts uint16 `json:"configured_voltage_mv"`
+}
+
+// SMBIOS structure types, see the DMTF SMBIOS specification (DSP0134)
+const (
+	smbiosTypeMemoryArray  = 16
+	smbiosTypeMemoryDevice = 17
+	smbiosTypeEndOfTable   = 127
+)
+
+// smbiosArrayLocations maps the location field of type 16 structures to a
+// name
+var smbiosArrayLocations = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "System board or motherboard",
+	0x04: "ISA add-on card",
+	0x05: "EISA add-on card",
+	0x06: "PCI add-on card",
+	0x07: "MCA add-on card",
+	0x08: "PCMCIA add-on card",
+	0x09: "Proprietary add-on card",
+	0x0A: "NuBus",
+	0xA0: "PC-98/C20 add-on card",
+	0xA1: "PC-98/C24 add-on card",
+	0xA2: "PC-98/E add-on card",
+	0xA3: "PC-98/Local bus add-on card",
+	0xA4: "CXL add-on card",
+}
+
+// smbiosArrayUses maps the use field of type 16 structures to a name
+var smbiosArrayUses = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "System memory",
+	0x04: "Video memory",
+	0x05: "Flash memory",
+	0x06: "Non-volatile RAM",
+	0x07: "Cache memory",
+}
+
+// smbiosErrorCorrections maps the memory error correction field of type 16
+// structures to a name
+var smbiosErrorCorrections = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "None",
+	0x04: "Parity",
+	0x05: "Single-bit ECC",
+	0x06: "Multi-bit ECC",
+	0x07: "CRC",
+}
+
+// smbiosFormFactors maps the form factor field of type 17 structures to a
+// name
+var smbiosFormFactors = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "SIMM",
+	0x04: "SIP",
+	0x05: "Chip",
+	0x06: "DIP",
+	0x07: "ZIP",
+	0x08: "Proprietary Card",
+	0x09: "DIMM",
+	0x0A: "TSOP",
+	0x0B: "Row of chips",
+	0x0C: "RIMM",
+	0x0D: "SODIMM",
+	0x0E: "SRIMM",
+	0x0F: "FB-DIMM",
+	0x10: "Die",
+	0x11: "CAMM",
+}
+
+// smbiosMemoryTypes maps the memory type field of type 17 structures to a
+// name
+var smbiosMemoryTypes = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "DRAM",
+	0x04: "EDRAM",
+	0x05: "VRAM",
+	0x06: "SRAM",
+	0x07: "RAM",
+	0x08: "ROM",
+	0x09: "Flash",
+	0x0A: "EEPROM",
+	0x0B: "FEPROM",
+	0x0C: "EPROM",
+	0x0D: "CDRAM",
+	0x0E: "3DRAM",
+	0x0F: "SDRAM",
+	0x10: "SGRAM",
+	0x11: "RDRAM",
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x14: "DDR2 FB-DIMM",
+	0x18: "DDR3",
+	0x19: "FBD2",
+	0x1A: "DDR4",
+	0x1B: "LPDDR",
+	0x1C: "LPDDR2",
+	0x1D: "LPDDR3",
+	0x1E: "LPDDR4",
+	0x1F: "Logical non-volatile device",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
+
+// smbiosStructure is a structure of the SMBIOS table: its formatted area
+// (starting with the 4-byte header) and its strings
+type smbiosStructure struct {
+	structType byte
+	handle     uint16
+	formatted  []byte
+	strings    []string
+}
+
+// byteAt returns the byte at the given offset of the formatted area, and
+// whether the structure is long enough to hold it; fields added by later
+// versions of the specification are missing from older structures
+func (s smbiosStructure) byteAt(offset int) (byte, bool) {
+	if offset+1 > len(s.formatted) {
+		return 0, false
+	}
+	return s.formatted[offset], true
+}
+
+// wordAt returns the little-endian word at the given offset
+func (s smbiosStructure) wordAt(offset int) (uint16, bool) {
+	if offset+2 > len(s.formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(s.formatted[offset:]), true
+}
+
+// dwordAt returns the little-endian double word at the given offset
+func (s smbiosStructure) dwordAt(offset int) (uint32, bool) {
+	if offset+4 > len(s.formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(s.formatted[offset:]), true
+}
+
+// qwordAt returns the little-endian quad word at the given offset
+func (s smbiosStructure) qwordAt(offset int) (uint64, bool) {
+	if offset+8 > len(s.formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(s.formatted[offset:]), true
+}
+
+// stringAt returns the string referenced by the byte at the given offset.
+// Strings are numbered from 1, 0 meaning no string.
+func (s smbiosStructure) stringAt(offset int) string {
+	index, ok := s.byteAt(offset)
+	if !ok || index == 0 || int(index) > len(s.strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.strings[index-1])
+}
+
+// parseSMBIOSStructures splits an SMBIOS structure table (eg. the content of
+// /sys/firmware/dmi/tables/DMI) into its structures.  Each structure is made
+// of a formatted area, whose length is in its header, followed by a set of
+// NUL-terminated strings ending with an additional NUL.
+func parseSMBIOSStructures(table []byte) ([]smbiosStructure, error) {
+	var structures []smbiosStructure
+
+	for offset := 0; offset+4 <= len(table); {
+		length := int(table[offset+1])
+		if length < 4 || offset+length > len(table) {
+			return nil, fmt.Errorf("invalid SMBIOS structure length %d at offset %d", length, offset)
+		}
+		s := smbiosStructure{
+			structType: table[offset],
+			handle:     binary.LittleEndian.Uint16(table[offset+2:]),
+			formatted:  table[offset : offset+length],
+		}
+
+		// the string set ends with a double NUL, which is also how an empty
+		// string set is represented
+		end := offset + length
+		for {
+			if end+2 > len(table) {
+				return nil, fmt.Errorf("unterminated SMBIOS structure at offset %d", offset)
+			}
+			if table[end] == 0 && table[end+1] == 0 {
+				break
+			}
+			end++
+		}
+		if end > offset+length {
+			s.strings = strings.Split(string(table[offset+length:end]), "\x00")
+		}
+
+		structures = append(structures, s)
+		if s.structType == smbiosTypeEndOfTable {
+			break
+		}
+		offset = end + 2
+	}
+
+	return structures, nil
+}
+
+// parseMemoryInventory decodes the memory arrays and devices (types 16 and
+// 17) of an SMBIOS structure table
+func parseMemoryInventory(table []byte) (*MemoryInventory, error) {
+	structures, err := parseSMBIOSStructures(table)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &MemoryInventory{
+		Arrays:  []MemoryArray{},
+		Modules: []MemoryModule{},
+	}
+	for _, s := range structures {
+		switch s.structType {
+		case smbiosTypeMemoryArray:
+			inventory.Arrays = append(inventory.Arrays, decodeMemoryArray(s))
+		case smbiosTypeMemoryDevice:
+			inventory.Modules = append(inventory.Modules, decodeMemoryModule(s))
+		}
+	}
+
+	return inventory, nil
+}
+
+// decodeMemoryArray decodes a type 16 structure
+func decodeMemoryArray(s smbiosStructure) MemoryArray {
+	array := MemoryArray{Handle: s.handle}
+
+	if location, ok := s.byteAt(0x04); ok {
+		array.Location = smbiosArrayLocations[location]
+	}
+	if use, ok := s.byteAt(0x05); ok {
+		array.Use = smbiosArrayUses[use]
+	}
+	if ecc, ok := s.byteAt(0x06); ok {
+		array.ErrorCorrection = smbiosErrorCorrections[ecc]
+	}
+	// the maximum capacity is in KB, with 0x80000000 meaning it is in the
+	// extended maximum capacity, in bytes
+	if capacity, ok := s.dwordAt(0x07); ok {
+		if capacity == 0x80000000 {
+			array.MaxCapacityBytes, _ = s.qwordAt(0x0F)
+		} else {
+			array.MaxCapacityBytes = uint64(capacity) * 1024
+		}
+	}
+	array.Slots, _ = s.wordAt(0x0D)
+
+	return array
+}
+
+// decodeMemoryModule decodes a type 17 structure
+func decodeMemoryModule(s smbiosStructure) MemoryModule {
+	module := MemoryModule{
+		Locator:      s.stringAt(0x10),
+		BankLocator:  s.stringAt(0x11),
+		Manufacturer: s.stringAt(0x17),
+		SerialNumber: s.stringAt(0x18),
+		AssetTag:     s.stringAt(0x19),
+		PartNumber:   s.stringAt(0x1A),
+	}
+	module.ArrayHandle, _ = s.wordAt(0x04)
+
+	// widths are 0xFFFF when unknown
+	if width, ok := s.wordAt(0x08); ok && width != 0xFFFF {
+		module.TotalWidthBits = width
+	}
+	if width, ok := s.wordAt(0x0A); ok && width != 0xFFFF {
+		module.DataWidthBits = width
+	}
+	module.ECC = module.DataWidthBits != 0 && module.TotalWidthBits > module.DataWidthBits
+
+	// the size is 0 for an empty slot and 0xFFFF when unknown; bit 15 tells
+	// whether it is in KB or MB, and 0x7FFF that it is in the extended size
+	if size, ok := s.wordAt(0x0C); ok && size != 0xFFFF {
+		switch {
+		case size == 0x7FFF:
+			if extended, ok := s.dwordAt(0x1C); ok {
+				module.SizeBytes = uint64(extended&0x7FFFFFFF) * 1024 * 1024
+			}
+		case size&0x8000 != 0:
+			module.SizeBytes = uint64(size&0x7FFF) * 1024
+		default:
+			module.SizeBytes = uint64(size) * 1024 * 1024
+		}
+	}
+
+	if formFactor, ok := s.byteAt(0x0E); ok {
+		module.FormFactor = smbiosFormFactors[formFactor]
+	}
+	if memoryType, ok := s.byteAt(0x12); ok {
+		module.Type = smbiosMemoryTypes[memoryType]
+	}
+
+	// speeds of 0xFFFF are in the extended speed fields
+	module.SpeedMTs = smbiosSpeed(s, 0x15, 0x54)
+	module.ConfiguredSpeedMTs = smbiosSpeed(s, 0x20, 0x58)
+
+	if attributes, ok := s.byteAt(0x1B); ok {
+		module.Rank = attributes & 0x0F
+	}
+	module.ConfiguredVoltageMillivolts, _ = s.wordAt(0x26)
+
+	return module
+}
+
+// smbiosSpeed reads a speed word at the given offset, falling back to the
+// double word at extendedOffset when it is 0xFFFF
+func smbiosSpeed(s smbiosStructure, offset, extendedOffset int) uint32 {
+	speed, ok := s.wordAt(offset)
+	if !ok {
+		return 0
+	}
+	if speed != 0xFFFF {
+		return uint32(speed)
+	}
+	extended, _ := s.dwordAt(extendedOffset)
+	return extended & 0x7FFFFFFF
+}
@@ -0,0 +1,27 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// getMemoryInventory decodes the memory arrays and modules from the SMBIOS
+// structure table exposed by the kernel.  The table is only readable by root,
+// and is missing on some platforms (eg. in most containers): the inventory is
+// then not available, and nil is returned without an error.
+func getMemoryInventory() (*MemoryInventory, error) {
+	table, err := ioutil.ReadFile(prefix + "/sys/firmware/dmi/tables/DMI")
+	if os.IsPermission(err) || os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMemoryInventory(table)
+}
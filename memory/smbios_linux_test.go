@@ -0,0 +1,41 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetMemoryInventory(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+
+	// no SMBIOS table, eg. in a container
+	inventory, err := getMemoryInventory()
+	require.NoError(t, err)
+	require.Nil(t, inventory)
+
+	table, err := ioutil.ReadFile(filepath.Join("testdata", "dmi-synthetic.bin"))
+	require.NoError(t, err)
+	utils.WriteTestFiles(t, prefix, map[string]string{"sys/firmware/dmi/tables/DMI": string(table)})
+	inventory, err = getMemoryInventory()
+	require.NoError(t, err)
+	require.NotNil(t, inventory)
+
+	if os.Geteuid() != 0 {
+		// the table is only readable by root
+		require.NoError(t, os.Chmod(filepath.Join(prefix, "sys/firmware/dmi/tables/DMI"), 0))
+		inventory, err = getMemoryInventory()
+		require.NoError(t, err)
+		require.Nil(t, inventory)
+	}
+}
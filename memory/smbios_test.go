@@ -0,0 +1,147 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseCapturedMemoryInventory decodes the tables captured on real
+// hosts: each testdata/dmi-captured-<host>.bin is a copy of
+// /sys/firmware/dmi/tables/DMI (`sudo cat /sys/firmware/dmi/tables/DMI`),
+// next to a dmi-captured-<host>.json holding the expected MemoryInventory,
+// checked against the output of `dmidecode -t 16,17` on the same host
+func TestParseCapturedMemoryInventory(t *testing.T) {
+	tables, err := filepath.Glob(filepath.Join("testdata", "dmi-captured-*.bin"))
+	require.NoError(t, err)
+	require.NotEmpty(t, tables, "no captured SMBIOS table in testdata")
+
+	for _, path := range tables {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			table, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			content, err := ioutil.ReadFile(strings.TrimSuffix(path, ".bin") + ".json")
+			require.NoError(t, err)
+			var expected MemoryInventory
+			require.NoError(t, json.Unmarshal(content, &expected))
+
+			inventory, err := parseMemoryInventory(table)
+			require.NoError(t, err)
+			require.Equal(t, &expected, inventory)
+		})
+	}
+}
+
+// testdata/dmi-synthetic.bin is a hand-built SMBIOS 3.x structure table
+// covering layouts rarely found together on a single host: a BIOS
+// information structure, a memory array and four memory devices, ie. two
+// DDR5 RDIMMs (one sized through the extended size and speed fields), an
+// empty slot and an SMBIOS 2.3 era DDR SODIMM lacking the newer fields.  Its
+// strings are placeholders.
+func TestParseMemoryInventory(t *testing.T) {
+	table, err := ioutil.ReadFile(filepath.Join("testdata", "dmi-synthetic.bin"))
+	require.NoError(t, err)
+
+	inventory, err := parseMemoryInventory(table)
+	require.NoError(t, err)
+	require.Equal(t, &MemoryInventory{
+		Arrays: []MemoryArray{
+			{
+				Handle:           0x1000,
+				Location:         "System board or motherboard",
+				Use:              "System memory",
+				ErrorCorrection:  "Multi-bit ECC",
+				MaxCapacityBytes: 2 * 1024 * 1024 * 1024 * 1024,
+				Slots:            16,
+			},
+		},
+		Modules: []MemoryModule{
+			{
+				ArrayHandle:                 0x1000,
+				Locator:                     "P0 CHANNEL A DIMM 0",
+				BankLocator:                 "BANK 0",
+				SizeBytes:                   16 * 1024 * 1024 * 1024,
+				FormFactor:                  "DIMM",
+				Type:                        "DDR5",
+				SpeedMTs:                    4800,
+				ConfiguredSpeedMTs:          4400,
+				Manufacturer:                "Samsung",
+				PartNumber:                  "M321R2GA3BB6-CQKVG",
+				SerialNumber:                "80CE0123ABCD",
+				AssetTag:                    "P0_DIMM_A0_AssetTag",
+				Rank:                        1,
+				TotalWidthBits:              80,
+				DataWidthBits:               64,
+				ECC:                         true,
+				ConfiguredVoltageMillivolts: 1100,
+			},
+			{
+				ArrayHandle:  0x1000,
+				Locator:      "P0 CHANNEL A DIMM 1",
+				BankLocator:  "BANK 1",
+				FormFactor:   "DIMM",
+				Type:         "Unknown",
+				Manufacturer: "NO DIMM",
+				PartNumber:   "NO DIMM",
+				SerialNumber: "NO DIMM",
+				AssetTag:     "NO DIMM",
+			},
+			{
+				ArrayHandle:                 0x1000,
+				Locator:                     "P0 CHANNEL B DIMM 0",
+				BankLocator:                 "BANK 2",
+				SizeBytes:                   64 * 1024 * 1024 * 1024,
+				FormFactor:                  "DIMM",
+				Type:                        "DDR5",
+				SpeedMTs:                    8000,
+				ConfiguredSpeedMTs:          6400,
+				Manufacturer:                "SK Hynix",
+				PartNumber:                  "HMCG88AEBRA107N",
+				SerialNumber:                "12345678",
+				AssetTag:                    "Not Specified",
+				Rank:                        4,
+				TotalWidthBits:              80,
+				DataWidthBits:               64,
+				ECC:                         true,
+				ConfiguredVoltageMillivolts: 1100,
+			},
+			{
+				ArrayHandle:    0x1000,
+				Locator:        "SODIMM0",
+				BankLocator:    "BANK 3",
+				SizeBytes:      512 * 1024,
+				FormFactor:     "SODIMM",
+				Type:           "DDR",
+				SpeedMTs:       400,
+				Manufacturer:   "Kingston",
+				PartNumber:     "KVR400",
+				SerialNumber:   "DEADBEEF",
+				AssetTag:       "None",
+				TotalWidthBits: 64,
+				DataWidthBits:  64,
+			},
+		},
+	}, inventory)
+}
+
+func TestParseMemoryInventoryTruncated(t *testing.T) {
+	table, err := ioutil.ReadFile(filepath.Join("testdata", "dmi-synthetic.bin"))
+	require.NoError(t, err)
+
+	// cut in the middle of the strings of the first structure
+	_, err = parseMemoryInventory(table[:0x30])
+	require.Error(t, err)
+
+	// cut in the middle of the formatted area of the second structure
+	_, err = parseMemoryInventory(table[:0x48])
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+// Swap describes the swap devices of the host, the zram devices and the
+// zswap configuration
+type Swap struct {
+	// Devices lists the active swap devices from /proc/swaps
+	Devices []SwapDevice `json:"devices"`
+	// Zram lists the zram devices, whether they are used for swap or not
+	Zram []ZramDevice `json:"zram"`
+	// Zswap is the zswap configuration, nil if zswap is not supported by the
+	// kernel
+	Zswap *Zswap `json:"zswap"`
+}
+
+// SwapDevice is an active swap partition or file
+type SwapDevice struct {
+	// Name is the path of the device or file
+	Name string `json:"name"`
+	// Type is "partition" or "file"
+	Type string `json:"type"`
+	// SizeBytes is the size of the swap area
+	SizeBytes uint64 `json:"size_bytes"`
+	// UsedBytes is the amount of swap in use
+	UsedBytes uint64 `json:"used_bytes"`
+	// Priority is the swap priority, higher priorities being used first
+	Priority int64 `json:"priority"`
+	// Zram tells whether the device is a zram device
+	Zram bool `json:"zram"`
+}
+
+// ZramDevice is a compressed RAM block device from /sys/block/zramN
+type ZramDevice struct {
+	// Name is the name of the block device, eg. "zram0"
+	Name string `json:"name"`
+	// DiskSizeBytes is the uncompressed capacity of the device
+	DiskSizeBytes uint64 `json:"disk_size_bytes"`
+	// CompressionAlgorithm is the selected compression algorithm
+	CompressionAlgorithm string `json:"compression_algorithm"`
+	// OriginalDataBytes is the uncompressed size of the stored data
+	OriginalDataBytes uint64 `json:"original_data_bytes"`
+	// CompressedDataBytes is the compressed size of the stored data
+	CompressedDataBytes uint64 `json:"compressed_data_bytes"`
+	// MemUsedBytes is the memory allocated to store the data, including
+	// fragmentation and metadata
+	MemUsedBytes uint64 `json:"mem_used_bytes"`
+	// MemLimitBytes is the maximum memory the device may use, 0 if unlimited
+	MemLimitBytes uint64 `json:"mem_limit_bytes"`
+	// MemUsedMaxBytes is the peak of MemUsedBytes
+	MemUsedMaxBytes uint64 `json:"mem_used_max_bytes"`
+}
+
+// Zswap is the zswap configuration from /sys/module/zswap/parameters
+type Zswap struct {
+	// Enabled tells whether zswap is enabled
+	Enabled bool `json:"enabled"`
+	// Compressor is the compression algorithm
+	Compressor string `json:"compressor"`
+	// Zpool is the allocator of the compressed pool
+	Zpool string `json:"zpool"`
+	// MaxPoolPercent is the maximum size of the pool, in percent of the RAM
+	MaxPoolPercent uint64 `json:"max_pool_percent"`
+	// AcceptThresholdPercent is the pool usage, in percent of its maximum
+	// size, under which zswap accepts pages again once full
+	AcceptThresholdPercent uint64 `json:"accept_threshold_percent"`
+	// ShrinkerEnabled tells whether cold pages are written back to the
+	// swap device under memory pressure (kernels 6.8+)
+	ShrinkerEnabled bool `json:"shrinker_enabled"`
+}
@@ -0,0 +1,147 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+// zramRegex recognizes zram block devices, eg. `zram0`
+var zramRegex = regexp.MustCompile("^zram([0-9]+)$")
+
+// getSwap reads the swap devices from /proc/swaps, the zram devices from
+// /sys/block/zramN and the zswap configuration from
+// /sys/module/zswap/parameters
+func getSwap() (*Swap, error) {
+	devices, err := readSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Swap{
+		Devices: devices,
+		Zram:    readZramDevices(),
+		Zswap:   readZswap(),
+	}, nil
+}
+
+// readSwaps parses /proc/swaps, whose sizes are in KB:
+//
+//	Filename				Type		Size		Used		Priority
+//	/dev/dm-1                               partition	8388604		0		-2
+func readSwaps() ([]SwapDevice, error) {
+	file, err := os.Open(prefix + "/proc/swaps")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	devices := []SwapDevice{}
+	scanner := bufio.NewScanner(file)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		device := SwapDevice{
+			Name: utils.UnescapeOctal(fields[0]),
+			Type: fields[1],
+		}
+		if size, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			device.SizeBytes = size * 1024
+		}
+		if used, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+			device.UsedBytes = used * 1024
+		}
+		device.Priority, _ = strconv.ParseInt(fields[4], 10, 64)
+		device.Zram = strings.HasPrefix(device.Name, "/dev/") && zramRegex.MatchString(device.Name[len("/dev/"):])
+		devices = append(devices, device)
+	}
+
+	return devices, scanner.Err()
+}
+
+// readZramDevices reads the zram devices in /sys/block, sorted by number
+func readZramDevices() []ZramDevice {
+	zram := []ZramDevice{}
+
+	dirents, err := os.ReadDir(prefix + "/sys/block")
+	if err != nil {
+		return zram
+	}
+
+	numbers := map[string]uint64{}
+	for _, dirent := range dirents {
+		submatches := zramRegex.FindStringSubmatch(dirent.Name())
+		if submatches == nil {
+			continue
+		}
+		number, err := strconv.ParseUint(submatches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		numbers[dirent.Name()] = number
+
+		dir := "/sys/block/" + dirent.Name() + "/"
+		device := ZramDevice{Name: dirent.Name()}
+		device.DiskSizeBytes, _ = readUint(dir + "disksize")
+		if algorithm, ok := readString(dir + "comp_algorithm"); ok {
+			device.CompressionAlgorithm = selectedMode(algorithm)
+		}
+		// mm_stat holds orig_data_size compr_data_size mem_used_total
+		// mem_limit mem_used_max same_pages pages_compacted huge_pages
+		if mmStat, ok := readString(dir + "mm_stat"); ok {
+			fields := strings.Fields(mmStat)
+			for i, value := range []*uint64{
+				&device.OriginalDataBytes,
+				&device.CompressedDataBytes,
+				&device.MemUsedBytes,
+				&device.MemLimitBytes,
+				&device.MemUsedMaxBytes,
+			} {
+				if i < len(fields) {
+					*value, _ = strconv.ParseUint(fields[i], 10, 64)
+				}
+			}
+		}
+		zram = append(zram, device)
+	}
+	sort.Slice(zram, func(i, j int) bool { return numbers[zram[i].Name] < numbers[zram[j].Name] })
+
+	return zram
+}
+
+// readZswap reads the zswap parameters, or returns nil if the kernel does not
+// support zswap
+func readZswap() *Zswap {
+	const dir = "/sys/module/zswap/parameters/"
+
+	enabled, ok := readString(dir + "enabled")
+	if !ok {
+		return nil
+	}
+
+	zswap := &Zswap{Enabled: enabled == "Y"}
+	zswap.Compressor, _ = readString(dir + "compressor")
+	zswap.Zpool, _ = readString(dir + "zpool")
+	zswap.MaxPoolPercent, _ = readUint(dir + "max_pool_percent")
+	zswap.AcceptThresholdPercent, _ = readUint(dir + "accept_threshold_percent")
+	if shrinkerEnabled, ok := readString(dir + "shrinker_enabled"); ok {
+		zswap.ShrinkerEnabled = shrinkerEnabled == "Y"
+	}
+
+	return zswap
+}
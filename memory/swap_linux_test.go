@@ -0,0 +1,76 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/gohai/utils"
+)
+
+func TestGetSwap(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/swaps": `Filename				Type		Size		Used		Priority
+/dev/zram0                              partition	4194300		1048576		100
+/dev/dm-1                               partition	8388604		0		-2
+/var/swap\040file                       file		2097148		512		-3
+`,
+		"sys/block/zram0/disksize":                             "4294967296\n",
+		"sys/block/zram0/comp_algorithm":                       "lzo lzo-rle lz4 lz4hc 842 [zstd]\n",
+		"sys/block/zram0/mm_stat":                              "1073741824 268435456 285212672        0 301989888     4096        0        0        0\n",
+		"sys/block/zram10/disksize":                            "0\n",
+		"sys/block/zram2/disksize":                             "1073741824\n",
+		"sys/block/sda/size":                                   "1000\n",
+		"sys/module/zswap/parameters/enabled":                  "N\n",
+		"sys/module/zswap/parameters/compressor":               "lzo\n",
+		"sys/module/zswap/parameters/zpool":                    "zbud\n",
+		"sys/module/zswap/parameters/max_pool_percent":         "20\n",
+		"sys/module/zswap/parameters/accept_threshold_percent": "90\n",
+	})
+
+	swap, err := getSwap()
+	require.NoError(t, err)
+	require.Equal(t, &Swap{
+		Devices: []SwapDevice{
+			{Name: "/dev/zram0", Type: "partition", SizeBytes: 4194300 * 1024, UsedBytes: 1048576 * 1024, Priority: 100, Zram: true},
+			{Name: "/dev/dm-1", Type: "partition", SizeBytes: 8388604 * 1024, Priority: -2},
+			{Name: "/var/swap file", Type: "file", SizeBytes: 2097148 * 1024, UsedBytes: 512 * 1024, Priority: -3},
+		},
+		Zram: []ZramDevice{
+			{
+				Name:                 "zram0",
+				DiskSizeBytes:        4294967296,
+				CompressionAlgorithm: "zstd",
+				OriginalDataBytes:    1073741824,
+				CompressedDataBytes:  268435456,
+				MemUsedBytes:         285212672,
+				MemUsedMaxBytes:      301989888,
+			},
+			{Name: "zram2", DiskSizeBytes: 1073741824},
+			{Name: "zram10"},
+		},
+		Zswap: &Zswap{
+			Compressor:             "lzo",
+			Zpool:                  "zbud",
+			MaxPoolPercent:         20,
+			AcceptThresholdPercent: 90,
+		},
+	}, swap)
+}
+
+func TestGetSwapNoSwap(t *testing.T) {
+	utils.SetTestPrefix(t, &prefix)
+	utils.WriteTestFiles(t, prefix, map[string]string{
+		"proc/swaps": "Filename				Type		Size		Used		Priority\n",
+	})
+
+	swap, err := getSwap()
+	require.NoError(t, err)
+	require.Equal(t, &Swap{Devices: []SwapDevice{}, Zram: []ZramDevice{}}, swap)
+}
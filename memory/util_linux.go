@@ -0,0 +1,52 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package memory
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+var prefix = "" // only used for testing
+
+// readString reads a whitespace-trimmed string from the file at the given
+// absolute path
+func readString(path string) (string, bool) {
+	content, err := ioutil.ReadFile(prefix + path)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(content)), true
+}
+
+// readUint reads an unsigned integer from the file at the given absolute path
+func readUint(path string) (uint64, bool) {
+	content, ok := readString(path)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// selectedMode returns the selected value of a sysfs file listing the
+// possible values with the selected one in brackets, eg. `always [madvise]
+// never`
+func selectedMode(content string) string {
+	for _, mode := range strings.Fields(content) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]")
+		}
+	}
+	return content
+}
@@ -0,0 +1,159 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Cgroup is a cgroup hierarchy a process belongs to, along with where that
+// hierarchy is mounted
+type Cgroup struct {
+	// Version is the cgroup version of the hierarchy (1 or 2)
+	Version int
+	// Controllers lists the controllers bound to the hierarchy (v1 only)
+	Controllers []string
+	// Path is the cgroup of the process, relative to the hierarchy root, as
+	// found in /proc/[pid]/cgroup
+	Path string
+	// MountDir is the directory where the hierarchy is mounted, or an empty
+	// string if it is not mounted
+	MountDir string
+	// Dir is the directory of the cgroup of the process, or an empty string
+	// if it is not mounted
+	Dir string
+}
+
+// GetCgroups returns the cgroups the process with the given PID belongs to
+// (the current process if pid is 0), resolved against the mounts of the
+// current process.  prefix is prepended to every path read, and to the
+// returned directories; it is only used in tests.
+func GetCgroups(prefix string, pid int) ([]Cgroup, error) {
+	proc := "self"
+	if pid != 0 {
+		proc = fmt.Sprint(pid)
+	}
+
+	content, err := ioutil.ReadFile(prefix + "/proc/" + proc + "/cgroup")
+	if err != nil {
+		return nil, err
+	}
+
+	mounts, err := ReadMountInfo(prefix + "/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	var cgroups []Cgroup
+	for _, line := range strings.Split(string(content), "\n") {
+		// lines use the format `hierarchy-ID:controller-list:cgroup-path`
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		cgroup := Cgroup{Version: 1, Path: fields[2]}
+		if fields[0] == "0" && fields[1] == "" {
+			cgroup.Version = 2
+		} else {
+			cgroup.Controllers = strings.Split(fields[1], ",")
+		}
+
+		for _, mount := range mounts {
+			if !cgroup.matchesMount(mount) {
+				continue
+			}
+			// the root of the mount is not "/" when the hierarchy is only
+			// partially visible, eg. in a container without cgroup namespace
+			rel := cgroup.Path
+			if mount.Root != "/" {
+				if rel != mount.Root && !strings.HasPrefix(rel, mount.Root+"/") {
+					continue
+				}
+				rel = strings.TrimPrefix(rel, mount.Root)
+			}
+			cgroup.MountDir = prefix + mount.MountPoint
+			cgroup.Dir = filepath.Join(cgroup.MountDir, filepath.FromSlash(path.Clean("/"+rel)))
+			break
+		}
+
+		cgroups = append(cgroups, cgroup)
+	}
+
+	return cgroups, nil
+}
+
+// matchesMount returns whether the given mount is the hierarchy of c
+func (c Cgroup) matchesMount(mount MountInfo) bool {
+	if c.Version == 2 {
+		return mount.FSType == "cgroup2"
+	}
+	if mount.FSType != "cgroup" {
+		return false
+	}
+	// v1 hierarchies are told apart by their controllers, which are part of
+	// the superblock options (named hierarchies appear as `name=systemd` in both)
+	for _, controller := range c.Controllers {
+		found := false
+		for _, option := range mount.SuperOptions {
+			if option == controller {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Ancestors returns Dir and each of its parent directories up to MountDir,
+// starting with Dir.  Limits of cgroups apply to all of their descendants,
+// so the effective limit of a process is the most restrictive one found in
+// these directories.
+func (c Cgroup) Ancestors() []string {
+	if c.Dir == "" {
+		return nil
+	}
+
+	dirs := []string{c.Dir}
+	for dir := c.Dir; dir != c.MountDir; {
+		parent := filepath.Dir(dir)
+		if parent == dir || !strings.HasPrefix(parent, c.MountDir) {
+			break
+		}
+		dir = parent
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// FindCgroup returns the cgroup of the given controller (eg. "cpu",
+// "memory"): the v1 hierarchy the controller is bound to if any, the v2
+// hierarchy otherwise.  It returns nil if neither is mounted.
+func FindCgroup(cgroups []Cgroup, controller string) *Cgroup {
+	var unified *Cgroup
+	for i, cgroup := range cgroups {
+		if cgroup.Dir == "" {
+			continue
+		}
+		if cgroup.Version == 2 {
+			unified = &cgroups[i]
+			continue
+		}
+		for _, c := range cgroup.Controllers {
+			if c == controller {
+				return &cgroups[i]
+			}
+		}
+	}
+	return unified
+}
@@ -0,0 +1,91 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeProcFiles creates the given files under prefix
+
+func TestGetCgroupsV1(t *testing.T) {
+	prefix := t.TempDir()
+	WriteTestFiles(t, prefix, map[string]string{
+		"proc/self/cgroup": `4:memory:/docker/abcd
+3:cpu,cpuacct:/docker/abcd
+1:name=systemd:/docker/abcd
+0::/docker/abcd
+`,
+		// the cpu,cpuacct hierarchy is mounted with its cgroup as root, as in
+		// a container without cgroup namespace
+		"proc/self/mountinfo": `30 25 0:26 /docker/abcd /sys/fs/cgroup/memory ro,nosuid - cgroup cgroup rw,memory
+31 25 0:27 /docker/abcd /sys/fs/cgroup/cpu,cpuacct ro,nosuid - cgroup cgroup rw,cpu,cpuacct
+32 25 0:28 / /sys/fs/cgroup/systemd ro,nosuid - cgroup cgroup rw,xattr,name=systemd
+`,
+	})
+
+	cgroups, err := GetCgroups(prefix, 0)
+	require.NoError(t, err)
+	require.Equal(t, []Cgroup{
+		{
+			Version:     1,
+			Controllers: []string{"memory"},
+			Path:        "/docker/abcd",
+			MountDir:    prefix + "/sys/fs/cgroup/memory",
+			Dir:         prefix + "/sys/fs/cgroup/memory",
+		},
+		{
+			Version:     1,
+			Controllers: []string{"cpu", "cpuacct"},
+			Path:        "/docker/abcd",
+			MountDir:    prefix + "/sys/fs/cgroup/cpu,cpuacct",
+			Dir:         prefix + "/sys/fs/cgroup/cpu,cpuacct",
+		},
+		{
+			Version:     1,
+			Controllers: []string{"name=systemd"},
+			Path:        "/docker/abcd",
+			MountDir:    prefix + "/sys/fs/cgroup/systemd",
+			Dir:         prefix + "/sys/fs/cgroup/systemd/docker/abcd",
+		},
+		{
+			// the unified hierarchy is not mounted
+			Version: 2,
+			Path:    "/docker/abcd",
+		},
+	}, cgroups)
+
+	require.Equal(t, &cgroups[1], FindCgroup(cgroups, "cpu"))
+	require.Nil(t, FindCgroup(cgroups, "pids"))
+	require.Equal(t, []string{
+		prefix + "/sys/fs/cgroup/systemd/docker/abcd",
+		prefix + "/sys/fs/cgroup/systemd/docker",
+		prefix + "/sys/fs/cgroup/systemd",
+	}, cgroups[2].Ancestors())
+}
+
+func TestGetCgroupsV2(t *testing.T) {
+	prefix := t.TempDir()
+	WriteTestFiles(t, prefix, map[string]string{
+		"proc/42/cgroup":      "0::/kubepods.slice/pod1.slice/cri-containerd-1234.scope\n",
+		"proc/self/mountinfo": "35 24 0:30 / /sys/fs/cgroup rw,nosuid - cgroup2 cgroup2 rw,nsdelegate\n",
+	})
+
+	cgroups, err := GetCgroups(prefix, 42)
+	require.NoError(t, err)
+	require.Equal(t, []Cgroup{
+		{
+			Version:  2,
+			Path:     "/kubepods.slice/pod1.slice/cri-containerd-1234.scope",
+			MountDir: prefix + "/sys/fs/cgroup",
+			Dir:      prefix + "/sys/fs/cgroup/kubepods.slice/pod1.slice/cri-containerd-1234.scope",
+		},
+	}, cgroups)
+	require.Equal(t, &cgroups[0], FindCgroup(cgroups, "memory"))
+	require.Len(t, cgroups[0].Ancestors(), 4)
+}
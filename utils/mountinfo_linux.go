@@ -0,0 +1,141 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MountInfo is an entry of /proc/[pid]/mountinfo, see proc(5)
+type MountInfo struct {
+	// MountID is the unique ID of the mount
+	MountID uint64
+	// ParentID is the ID of the parent mount
+	ParentID uint64
+	// Major is the major number of the device holding the filesystem
+	Major uint64
+	// Minor is the minor number of the device holding the filesystem
+	Minor uint64
+	// Root is the directory of the filesystem which forms the root of this
+	// mount (eg. the source directory of a bind mount)
+	Root string
+	// MountPoint is where the filesystem is mounted
+	MountPoint string
+	// MountOptions are the per-mount options (eg. "rw", "noexec")
+	MountOptions []string
+	// OptionalFields are the propagation fields (eg. "shared:1")
+	OptionalFields []string
+	// FSType is the filesystem type (eg. "ext4", "nfs4")
+	FSType string
+	// Source is the mount source (eg. "/dev/sda1")
+	Source string
+	// SuperOptions are the per-superblock options
+	SuperOptions []string
+}
+
+// ReadMountInfo reads and parses the mountinfo file at the given path
+func ReadMountInfo(path string) ([]MountInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseMountInfo(file)
+}
+
+// ParseMountInfo parses the content of a mountinfo file, which uses the format
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func ParseMountInfo(r io.Reader) ([]MountInfo, error) {
+	var mounts []MountInfo
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := strings.Split(line, " ")
+		// the optional fields are terminated by a single hyphen
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep == -1 || len(fields) < sep+3 {
+			return nil, fmt.Errorf("could not parse mountinfo line %q", line)
+		}
+
+		mount := MountInfo{
+			Root:           UnescapeOctal(fields[3]),
+			MountPoint:     UnescapeOctal(fields[4]),
+			MountOptions:   strings.Split(fields[5], ","),
+			OptionalFields: fields[6:sep],
+			FSType:         fields[sep+1],
+			Source:         UnescapeOctal(fields[sep+2]),
+		}
+		if len(fields) > sep+3 {
+			mount.SuperOptions = strings.Split(fields[sep+3], ",")
+		}
+
+		var err error
+		if mount.MountID, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse mountinfo line %q: %s", line, err)
+		}
+		if mount.ParentID, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse mountinfo line %q: %s", line, err)
+		}
+		majorMinor := strings.SplitN(fields[2], ":", 2)
+		if len(majorMinor) != 2 {
+			return nil, fmt.Errorf("could not parse mountinfo line %q", line)
+		}
+		if mount.Major, err = strconv.ParseUint(majorMinor[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse mountinfo line %q: %s", line, err)
+		}
+		if mount.Minor, err = strconv.ParseUint(majorMinor[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("could not parse mountinfo line %q: %s", line, err)
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	if scanner.Err() != nil {
+		return nil, scanner.Err()
+	}
+
+	return mounts, nil
+}
+
+// UnescapeOctal decodes the octal escapes (eg. `\040` for a space) used
+// by the kernel for whitespace and backslashes in the paths of mountinfo,
+// /proc/swaps and other seq files
+func UnescapeOctal(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
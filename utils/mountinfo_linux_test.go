@@ -0,0 +1,68 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := ParseMountInfo(strings.NewReader(
+		`36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+37 36 0:30 / /media/My\040Disk ro,nosuid shared:7 master:2 - vfat /dev/sdb1 ro
+38 36 0:31 / /sys/fs/cgroup rw - cgroup2 cgroup2 rw,nsdelegate
+`))
+	require.NoError(t, err)
+	require.Equal(t, []MountInfo{
+		{
+			MountID:        36,
+			ParentID:       35,
+			Major:          98,
+			Minor:          0,
+			Root:           "/mnt1",
+			MountPoint:     "/mnt2",
+			MountOptions:   []string{"rw", "noatime"},
+			OptionalFields: []string{"master:1"},
+			FSType:         "ext3",
+			Source:         "/dev/root",
+			SuperOptions:   []string{"rw", "errors=continue"},
+		},
+		{
+			MountID:        37,
+			ParentID:       36,
+			Major:          0,
+			Minor:          30,
+			Root:           "/",
+			MountPoint:     "/media/My Disk",
+			MountOptions:   []string{"ro", "nosuid"},
+			OptionalFields: []string{"shared:7", "master:2"},
+			FSType:         "vfat",
+			Source:         "/dev/sdb1",
+			SuperOptions:   []string{"ro"},
+		},
+		{
+			MountID:        38,
+			ParentID:       36,
+			Major:          0,
+			Minor:          31,
+			Root:           "/",
+			MountPoint:     "/sys/fs/cgroup",
+			MountOptions:   []string{"rw"},
+			OptionalFields: []string{},
+			FSType:         "cgroup2",
+			Source:         "cgroup2",
+			SuperOptions:   []string{"rw", "nsdelegate"},
+		},
+	}, mounts)
+}
+
+func TestParseMountInfoInvalid(t *testing.T) {
+	_, err := ParseMountInfo(strings.NewReader("36 35 98:0 /mnt1 /mnt2 rw,noatime master:1\n"))
+	require.Error(t, err)
+}
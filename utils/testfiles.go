@@ -0,0 +1,36 @@
+// This file is licensed under the MIT License.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright © 2015 Kentaro Kuribayashi <kentarok@gmail.com>
+// Copyright 2014-present Datadog, Inc.
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// SetTestPrefix points prefix, the directory under which a package reads
+// /proc and /sys, to a fresh temporary directory until the end of the test
+func SetTestPrefix(t testing.TB, prefix *string) {
+	*prefix = t.TempDir()
+	t.Cleanup(func() { *prefix = "" })
+}
+
+// WriteTestFiles writes the given files under root, creating their parent
+// directories.  The files are keyed by their slash-separated path, eg.
+// "proc/meminfo".
+func WriteTestFiles(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}